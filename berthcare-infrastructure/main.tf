@@ -48,6 +48,7 @@ module "rds" {
   private_subnet_ids        = module.vpc.private_subnet_ids
   allowed_security_group_id = module.ecs.task_security_group_id
   backup_retention_period   = var.backup_retention_period
+  deletion_protection       = var.deletion_protection
 }
 
 module "dns" {
@@ -53,7 +53,7 @@ resource "aws_db_instance" "this" {
   publicly_accessible     = false
   skip_final_snapshot     = true
   apply_immediately       = true
-  deletion_protection     = false
+  deletion_protection     = var.deletion_protection
   copy_tags_to_snapshot   = true
 
   tags = {
@@ -49,3 +49,9 @@ variable "backup_retention_period" {
   description = "Backup retention period in days (must be >= 7 for compliance)."
   default     = 7
 }
+
+variable "deletion_protection" {
+  type        = bool
+  description = "Whether the database can be deleted only after turning this off (required in staging and production)."
+  default     = true
+}
@@ -0,0 +1,18 @@
+# Declarative policy rules, loaded by berthcare-policy alongside the rules
+# written in Go. See "Declarative rules" in the README for the format.
+
+rule "RDS-006" {
+  description   = "Staging and production RDS instances must enable deletion protection"
+  severity      = "error"
+  resource_type = "aws_db_instance"
+  attribute     = "deletion_protection"
+  operator      = "equals"
+  value         = true
+  environments  = ["staging", "production"]
+
+  trace {
+    feature  = "aws-staging-environment"
+    property = 2
+    name     = "RDS Security Compliance"
+  }
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestALBTLSPolicies runs ALB-001 against an HTTPS listener with each kind of
+// ELB security policy name, accepted only when it promises TLS 1.2 or later.
+func TestALBTLSPolicies(t *testing.T) {
+	engine, err := policy.NewEngine("ALB-001")
+	require.NoError(t, err)
+
+	cases := []struct {
+		policy string
+		ok     bool
+	}{
+		{"ELBSecurityPolicy-TLS-1-2-2017-01", true},
+		{"ELBSecurityPolicy-TLS-1-2-Ext-2018-06", true},
+		{"ELBSecurityPolicy-TLS-1-3-2021", true},
+		{"ELBSecurityPolicy-TLS13-1-2-2021-06", true},
+		{"ELBSecurityPolicy-TLS13-1-3-2021-06", true},
+		{"ELBSecurityPolicy-FS-1-2-2019-08", true},
+		{"ELBSecurityPolicy-FS-1-2-Res-2020-10", true},
+		{"ELBSecurityPolicy-2016-08", false},
+		{"ELBSecurityPolicy-TLS-1-1-2017-01", false},
+		{"ELBSecurityPolicy-FS-1-1-2019-08", false},
+		{"ELBSecurityPolicy-TLS13-1-0-2021-06", false},
+		{"ELBSecurityPolicy-TLS13-1-1-2021-06", false},
+	}
+	for _, c := range cases {
+		t.Run(c.policy, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"main.tf": fmt.Sprintf(`resource "aws_lb_listener" "https" {
+  port            = 443
+  protocol        = "HTTPS"
+  ssl_policy      = %q
+  certificate_arn = "arn:aws:acm:ca-central-1:123456789012:certificate/1"
+}
+`, c.policy)})
+			ws, err := policy.LoadWorkspace(dir)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, v := range engine.Run(ws) {
+				messages = append(messages, v.Message)
+			}
+			if c.ok {
+				require.Empty(t, messages)
+			} else {
+				require.Equal(t, []string{"ssl_policy must enforce TLS 1.2+"}, messages)
+			}
+		})
+	}
+}
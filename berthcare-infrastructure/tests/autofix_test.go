@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAutofix applies the fixes rules offer to small configurations and
+// re-runs each rule to confirm its violations are gone.
+func TestAutofix(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  string
+		env   string
+		files map[string]string
+		// want are lines the fixed main.tf must contain.
+		want []string
+	}{
+		{
+			name: "public access block flags",
+			rule: "S3-003",
+			files: map[string]string{"main.tf": `
+resource "aws_s3_bucket_public_access_block" "photos" {
+  bucket            = "photos" # keep this comment
+  block_public_acls = false
+}
+`},
+			want: []string{
+				`  bucket                  = "photos" # keep this comment`,
+				`  block_public_acls       = true`,
+				`  restrict_public_buckets = true`,
+			},
+		},
+		{
+			name: "bucket encryption",
+			rule: "S3-001",
+			files: map[string]string{"main.tf": `
+resource "aws_s3_bucket" "photos" {
+  bucket = "photos"
+}
+`},
+			want: []string{`        sse_algorithm = "AES256"`},
+		},
+		{
+			name: "bucket versioning",
+			rule: "S3-002",
+			files: map[string]string{"main.tf": `
+resource "aws_s3_bucket" "photos" {
+  bucket = "photos"
+
+  versioning {
+    enabled = false
+  }
+}
+`},
+			want: []string{`    enabled = true`},
+		},
+		{
+			name: "database encryption",
+			rule: "RDS-001",
+			files: map[string]string{"main.tf": `
+resource "aws_db_instance" "main" {
+  engine = "postgres"
+}
+`},
+			want: []string{`  storage_encrypted = true`},
+		},
+		{
+			name: "http listener redirect",
+			rule: "ALB-002",
+			files: map[string]string{"main.tf": `
+resource "aws_lb_listener" "http" {
+  port = 80
+
+  default_action {
+    type             = "forward"
+    target_group_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:targetgroup/app/1"
+  }
+}
+`},
+			want: []string{`  protocol = "HTTP"`, `    type = "redirect"`, `      status_code = "HTTP_301"`},
+		},
+		{
+			name: "certificate lifecycle",
+			rule: "ACM-001",
+			env:  "dev",
+			files: map[string]string{
+				"main.tf": `
+variable "domain_name" {
+  type = string
+}
+
+variable "route53_zone_id" {
+  type = string
+}
+
+resource "aws_acm_certificate" "this" {
+  domain_name       = var.domain_name
+  validation_method = "DNS"
+}
+`,
+				"environments/dev/terraform.tfvars": `
+domain_name     = "dev.berthcare.ca"
+route53_zone_id = "Z0123456789"
+`,
+			},
+			want: []string{`  lifecycle {`, `    create_before_destroy = true`},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			}
+
+			engine, err := policy.NewEngine(tc.rule)
+			require.NoError(t, err)
+			run := func() []policy.Violation {
+				ws, err := policy.LoadWorkspace(dir)
+				require.NoError(t, err)
+				return engine.Run(ws.WithEnvironment(tc.env))
+			}
+
+			violations := run()
+			require.NotEmpty(t, violations)
+			var fixes []*policy.Fix
+			for _, v := range violations {
+				require.NotNil(t, v.Fix, "expected a fix for %s", v)
+				fixes = append(fixes, v.Fix)
+			}
+
+			edits, err := policy.ApplyFixes(fixes)
+			require.NoError(t, err)
+			require.Len(t, edits, 1)
+			require.NoError(t, os.WriteFile(edits[0].Path, edits[0].After, 0o644))
+
+			for _, line := range tc.want {
+				require.Contains(t, string(edits[0].After), line+"\n")
+			}
+			require.Empty(t, run(), "expected %s to pass after fixing:\n%s", tc.rule, edits[0].After)
+		})
+	}
+
+	t.Run("declarative equals", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+resource "aws_dynamodb_table" "locks" {
+  name = "locks"
+}
+`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "dynamodb.hcl"), []byte(`
+rule "FIX-001" {
+  description   = "DynamoDB tables must enable point-in-time recovery"
+  severity      = "error"
+  resource_type = "aws_dynamodb_table"
+  attribute     = "point_in_time_recovery.enabled"
+  operator      = "equals"
+  value         = true
+}
+`), 0o644))
+
+		rules, err := policy.LoadPolicies(dir, nil)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+
+		ws, err := policy.LoadWorkspace(dir)
+		require.NoError(t, err)
+		violations := rules[0].Evaluate(ws)
+		require.Len(t, violations, 1)
+		require.NotNil(t, violations[0].Fix)
+		require.Equal(t, "set point_in_time_recovery.enabled = true", violations[0].Fix.Description)
+
+		edits, err := policy.ApplyFixes([]*policy.Fix{violations[0].Fix})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(edits[0].Path, edits[0].After, 0o644))
+
+		ws, err = policy.LoadWorkspace(dir)
+		require.NoError(t, err)
+		require.Empty(t, rules[0].Evaluate(ws))
+	})
+
+	t.Run("values from variables are not rewritten", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+resource "aws_s3_bucket_public_access_block" "photos" {
+  bucket                  = "photos"
+  block_public_acls       = var.block_public_acls
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+`), 0o644))
+
+		ws, err := policy.LoadWorkspace(dir)
+		require.NoError(t, err)
+		engine, err := policy.NewEngine("S3-003")
+		require.NoError(t, err)
+
+		violations := engine.Run(ws)
+		require.Len(t, violations, 1)
+		require.Nil(t, violations[0].Fix)
+	})
+}
@@ -0,0 +1,975 @@
+// Command berthcare-policy evaluates BerthCare's infrastructure compliance
+// rules against a Terraform root directory.
+//
+// Usage:
+//
+//	berthcare-policy check [--env NAME] [--rule ID]... [--format NAME[=PATH]]... [--baseline FILE] [--fail-on SEVERITY] [--workers N] [--rule-timeout DURATION] [--since REF] [DIR]
+//	berthcare-policy check --plan FILE [--allow-destroy ADDRESS]... [--max-destroy N] [--stateful-type TYPE]... [--rule ID]... [--format NAME[=PATH]]... [--baseline FILE] [--fail-on SEVERITY]
+//	berthcare-policy check --state FILE [--rule ID]... [--format NAME[=PATH]]... [--baseline FILE] [--fail-on SEVERITY]
+//	berthcare-policy baseline [--env NAME] [--rule ID]... [--file FILE] [--prune] [DIR]
+//	berthcare-policy fix [--env NAME] [--rule ID]... [--dry-run] [DIR]
+//	berthcare-policy drift --state FILE [DIR]
+//	berthcare-policy fmt [--write] [DIR]
+//	berthcare-policy validate [--plugin-dir DIR] [--terraform PATH] [--timeout DURATION] [DIR]
+//	berthcare-policy mutate [--rule ID]... [--policies DIR] [--verbose] [DIR]
+//	berthcare-policy trace [--output FILE] [--policies DIR] [TESTDIR]
+//	berthcare-policy rules [--policies DIR]
+//
+// check evaluates workspace-scoped rules once and environment-scoped rules
+// against every directory under DIR/environments, or only --env when given.
+// DIR defaults to the current directory. With --plan it instead evaluates the
+// rules that support plans against a plan written by terraform show -json.
+//
+// With --since, check reports only what the changes since the git revision
+// REF touch: violations of the blocks declared in changed files and of the
+// blocks that depend on them through references, module arguments and
+// module outputs, violations in changed files, and any violation the
+// changes introduce elsewhere, found by checking the same blocks of the tree
+// as it was at REF. Uncommitted and untracked files count as changed. Rules
+// check only those blocks, so the run is narrowed as well as the report.
+//
+// Rules are evaluated concurrently, by --workers at a time, one per CPU by
+// default. --rule-timeout fails a rule that takes longer than DURATION, such
+// as 30s, against one environment. An interrupt stops the evaluation.
+//
+// A plan fails PLAN-001 when it deletes or replaces a stateful resource, or
+// destroys more than --max-destroy resources in total. --stateful-type adds a
+// resource type to those treated as stateful. --allow-destroy approves the
+// destruction of one resource address; it must be given for every address
+// a plan that destroys data is meant to get through with.
+//
+// fix applies the fixes rules offer for violations with one obvious
+// resolution, such as a public access block missing block_public_acls =
+// true, rewriting the files in place and keeping their comments and layout.
+// --dry-run prints the changes as a unified diff instead, and exits 1 when
+// there are any. Suppressed violations are not fixed.
+//
+// With --state, check evaluates the rules that support state against the
+// attribute values recorded in a local version 4 state file, which catches
+// changes made outside Terraform. drift lists the resources such a state
+// file records that DIR no longer declares, and those DIR declares that have
+// no state entry, and exits 1 when there are any.
+//
+// fmt checks that every .tf, .tfvars and .hcl file in DIR is laid out the
+// way terraform fmt would lay it out, without needing terraform. It prints a
+// unified diff for each file that is not and exits 1 when there are any.
+// --write rewrites those files instead.
+//
+// validate runs terraform validate against the root module in DIR and every
+// module under DIR/modules, each in a copy of DIR in a temporary directory,
+// so that DIR is left alone. terraform init installs providers from the
+// mirror in --plugin-dir, which defaults to $TERRAFORM_PLUGIN_DIR, and never
+// from the network. Each terraform command must finish within --timeout.
+// validate prints terraform's diagnostics and exits 1 when any is an error.
+//
+// mutate makes security-relevant changes to the configuration in DIR, such
+// as making a database publicly accessible or setting a foreign region, one
+// at a time and in memory, and checks that each makes a rule report a
+// violation the configuration does not already have. It lists the
+// surviving mutants, which no rule caught, and the mutation score, and
+// exits 1 when any survive. --verbose also lists the mutants that were
+// caught.
+//
+// Every command that evaluates rules also loads the declarative rules in
+// DIR/policies, or in --policies, which must be given to load any with
+// --plan or --state. rules and trace include them when given --policies.
+//
+// trace writes a Markdown requirements traceability matrix joining the
+// properties and requirements each rule declares with the **Feature** and
+// **Validates** annotations on the tests in TESTDIR, which defaults to the
+// current directory. It exits 1 when a property has no test or a
+// requirement is never validated.
+//
+// --format selects a reporter: text (the default), json, sarif, junit or
+// github. It may be repeated to write several reports from one run; each
+// report goes to PATH when given and to standard output otherwise, and at
+// most one may use standard output. SARIF and github annotation paths are
+// relative to the working directory, so run from the repository root when
+// publishing them to GitHub.
+//
+// --baseline accepts the violations recorded in a baseline file, so that only
+// new ones fail the check. --fail-on sets the least serious severity that
+// fails it: error (the default), warning or info. Less serious violations are
+// still reported.
+//
+// baseline records the violations the configuration has today in
+// DIR/policy-baseline.json, or --file. Entries for rules and environments
+// that were not evaluated are kept. With --prune it only drops entries that
+// no longer occur, for refreshing the baseline after fixing violations
+// without accepting new ones.
+//
+// Exit codes:
+//
+//	0  no failing violations
+//	1  at least one rule reported a violation at or above --fail-on
+//	2  a Terraform file failed to parse, the command was misconfigured or
+//	   it was interrupted
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"berthcare-infrastructure/tests/policy"
+)
+
+const (
+	exitClean      = 0
+	exitViolations = 1
+	exitError      = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	switch args[0] {
+	case "check":
+		return check(args[1:], stdout, stderr)
+	case "baseline":
+		return baselineCommand(args[1:], stdout, stderr)
+	case "fix":
+		return fixCommand(args[1:], stdout, stderr)
+	case "drift":
+		return driftCommand(args[1:], stdout, stderr)
+	case "fmt":
+		return fmtCommand(args[1:], stdout, stderr)
+	case "validate":
+		return validateCommand(args[1:], stdout, stderr)
+	case "mutate":
+		return mutateCommand(args[1:], stdout, stderr)
+	case "trace":
+		return traceCommand(args[1:], stdout, stderr)
+	case "rules":
+		return listRules(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		usage(stdout)
+		return exitClean
+	default:
+		fmt.Fprintf(stderr, "berthcare-policy: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: berthcare-policy check [--env NAME | --plan FILE] [--rule ID]... [--format NAME[=PATH]]... [--baseline FILE] [--fail-on SEVERITY] [DIR]\n")
+	fmt.Fprintln(w, "       run options: [--workers N] [--rule-timeout DURATION], and for a DIR [--since REF]")
+	fmt.Fprintln(w, "       plan options: [--allow-destroy ADDRESS]... [--max-destroy N] [--stateful-type TYPE]...")
+	fmt.Fprintf(w, "       formats: %s\n", strings.Join(policy.ReporterNames(), ", "))
+	fmt.Fprintln(w, "       berthcare-policy check --state FILE [--rule ID]... [--format NAME[=PATH]]... [--baseline FILE] [--fail-on SEVERITY]")
+	fmt.Fprintln(w, "       berthcare-policy baseline [--env NAME] [--rule ID]... [--file FILE] [--prune] [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy fix [--env NAME] [--rule ID]... [--dry-run] [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy drift --state FILE [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy fmt [--write] [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy validate [--plugin-dir DIR] [--terraform PATH] [--timeout DURATION] [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy mutate [--rule ID]... [--policies DIR] [--verbose] [DIR]")
+	fmt.Fprintln(w, "       berthcare-policy trace [--output FILE] [--policies DIR] [TESTDIR]")
+	fmt.Fprintln(w, "       berthcare-policy rules [--policies DIR]")
+	fmt.Fprintln(w, "       check and baseline also take [--policies DIR], default DIR/policies")
+}
+
+// stringsFlag collects every value of a repeatable flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// output is one report requested with --format.
+type output struct {
+	reporter policy.Reporter
+	path     string
+}
+
+func parseOutputs(formats []string) ([]output, error) {
+	if len(formats) == 0 {
+		formats = []string{"text"}
+	}
+
+	var outputs []output
+	toStdout := 0
+	for _, format := range formats {
+		name, path, _ := strings.Cut(format, "=")
+		reporter, ok := policy.LookupReporter(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q (want one of %s)", name, strings.Join(policy.ReporterNames(), ", "))
+		}
+		if path == "" {
+			toStdout++
+		}
+		outputs = append(outputs, output{reporter: reporter, path: path})
+	}
+
+	if toStdout > 1 {
+		return nil, fmt.Errorf("at most one --format may write to standard output; give the others a path")
+	}
+	return outputs, nil
+}
+
+func writeOutputs(outputs []output, report *policy.Report, stdout io.Writer) error {
+	for _, out := range outputs {
+		if out.path == "" {
+			if err := out.reporter.Write(stdout, report); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f, err := os.Create(out.path)
+		if err != nil {
+			return err
+		}
+		if err := out.reporter.Write(f, report); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func check(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		env           = flags.String("env", "", "evaluate environment-scoped rules for this environment only")
+		plan          = flags.String("plan", "", "evaluate the plan in `FILE`, written by terraform show -json, instead of a directory")
+		state         = flags.String("state", "", "evaluate the values recorded in the state `FILE` instead of a directory")
+		baseline      = flags.String("baseline", "", "fail only on violations not recorded in this baseline `FILE`")
+		failOn        = flags.String("fail-on", string(policy.SeverityError), "fail on violations of this `SEVERITY` or worse")
+		maxDestroy    = flags.Int("max-destroy", policy.DefaultDestroyGuard.MaxDestroyed, "fail a plan that destroys more than `N` resources")
+		policies      = flags.String("policies", "", "load declarative rules from `DIR` (default DIR/"+policy.PoliciesDir+")")
+		workers       = flags.Int("workers", 0, "evaluate at most `N` rules at once (default one per CPU)")
+		ruleTimeout   = flags.Duration("rule-timeout", 0, "fail a rule that runs longer than `DURATION` against one environment")
+		since         = flags.String("since", "", "report only violations the changes since the git revision `REF` touch or introduce")
+		rules         stringsFlag
+		formats       stringsFlag
+		allowDestroy  stringsFlag
+		statefulTypes stringsFlag
+	)
+	flags.Var(&rules, "rule", "evaluate only this rule `ID` (repeatable)")
+	flags.Var(&formats, "format", "write a report as `NAME[=PATH]` (repeatable)")
+	flags.Var(&allowDestroy, "allow-destroy", "let the plan destroy the resource at `ADDRESS` (repeatable)")
+	flags.Var(&statefulTypes, "stateful-type", "also treat resources of `TYPE` as stateful (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+
+	threshold, err := policy.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: --fail-on: %v\n", err)
+		return exitError
+	}
+
+	outputs, err := parseOutputs(formats)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	from := target{
+		plan:  *plan,
+		state: *state,
+		since: *since,
+		guard: policy.DestroyGuard{
+			StatefulTypes: slices.Concat(policy.DefaultDestroyGuard.StatefulTypes, statefulTypes),
+			MaxDestroyed:  *maxDestroy,
+			Allowed:       allowDestroy,
+		},
+	}
+	if *plan == "" && (len(allowDestroy) > 0 || len(statefulTypes) > 0 || flagSet(flags, "max-destroy")) {
+		fmt.Fprintln(stderr, "berthcare-policy: --allow-destroy, --max-destroy and --stateful-type need --plan")
+		return exitError
+	}
+	if *workers < 0 || *ruleTimeout < 0 {
+		fmt.Fprintln(stderr, "berthcare-policy: --workers and --rule-timeout must not be negative")
+		return exitError
+	}
+
+	ws, report, code := evaluate("check", flags, *env, from, *policies, rules, execution{*workers, *ruleTimeout}, stderr)
+	if report == nil {
+		return code
+	}
+
+	if *baseline != "" {
+		b, err := policy.ReadBaseline(*baseline)
+		if err != nil {
+			fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+			return exitError
+		}
+		report.ApplyBaseline(b)
+		if len(report.Fixed) > 0 {
+			fmt.Fprintf(stderr, "berthcare-policy: %d baselined violation(s) no longer occur; run berthcare-policy baseline --prune to drop them\n", len(report.Fixed))
+		}
+	}
+
+	if err := writeOutputs(outputs, report, stdout); err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	switch {
+	case len(ws.Diagnostics()) > 0:
+		return exitError
+	case len(report.Failing(threshold)) > 0:
+		return exitViolations
+	default:
+		return exitClean
+	}
+}
+
+// baselineCommand writes the violations the configuration has today to a
+// baseline file, or with --prune drops the entries that have been fixed.
+func baselineCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("baseline", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		env      = flags.String("env", "", "refresh environment-scoped rules for this environment only")
+		file     = flags.String("file", "", "baseline `FILE` (default DIR/"+policy.BaselineFile+")")
+		prune    = flags.Bool("prune", false, "only drop fixed violations, never record new ones")
+		policies = flags.String("policies", "", "load declarative rules from `DIR` (default DIR/"+policy.PoliciesDir+")")
+		rules    stringsFlag
+	)
+	flags.Var(&rules, "rule", "refresh only this rule `ID` (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+
+	ws, report, code := evaluate("baseline", flags, *env, target{}, *policies, rules, execution{}, stderr)
+	if report == nil {
+		return code
+	}
+	if len(ws.Diagnostics()) > 0 {
+		fmt.Fprintln(stderr, "berthcare-policy: not writing a baseline while Terraform files fail to parse")
+		return exitError
+	}
+
+	path := *file
+	if path == "" {
+		path = filepath.Join(ws.Root, policy.BaselineFile)
+	}
+
+	existing, err := policy.ReadBaseline(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !*prune:
+		existing = &policy.Baseline{}
+	case err != nil:
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	updated := existing.Update(report)
+	if *prune {
+		updated = existing.Prune(report)
+	}
+	if err := updated.WriteFile(path); err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	fmt.Fprintf(stdout, "wrote %d violation(s) to %s (was %d)\n", len(updated.Entries), path, len(existing.Entries))
+	return exitClean
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// target is the plan or state file a command evaluates instead of a
+// directory, if any, and the destructive changes a plan may make. since is
+// the git revision a directory check is narrowed to the changes since.
+type target struct {
+	plan  string
+	state string
+	since string
+	guard policy.DestroyGuard
+}
+
+// execution is how the engine schedules the rules: how many it evaluates at
+// once, zero for one per CPU, and how long each may take, zero for no limit.
+type execution struct {
+	workers     int
+	ruleTimeout time.Duration
+}
+
+// evaluate loads the plan, or the directory named by the command's remaining
+// argument, and runs the selected rules against it, along with the
+// declarative rules in policies or the directory's policies/. It returns a nil report,
+// with the exit code to use, when the command cannot go on, including when
+// it is interrupted.
+func evaluate(command string, flags *flag.FlagSet, env string, from target, policies string, rules []string, exec execution, stderr io.Writer) (*policy.Workspace, *policy.Report, int) {
+	if flags.NArg() > 1 {
+		fmt.Fprintf(stderr, "berthcare-policy: %s takes at most one directory\n", command)
+		return nil, nil, exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	if policies == "" && from.plan == "" && from.state == "" {
+		policies = filepath.Join(dir, policy.PoliciesDir)
+	}
+	if !registerPolicies(policies, dir, flagSet(flags, "policies"), stderr) {
+		return nil, nil, exitError
+	}
+
+	engine, err := policy.NewEngine(rules...)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return nil, nil, exitError
+	}
+	engine.Workers = exec.workers
+	engine.RuleTimeout = exec.ruleTimeout
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if from.plan != "" || from.state != "" {
+		if flags.NArg() > 0 || env != "" || from.since != "" || from.plan != "" && from.state != "" {
+			fmt.Fprintln(stderr, "berthcare-policy: --plan and --state take neither a directory, --env, --since nor each other")
+			return nil, nil, exitError
+		}
+
+		var ws *policy.Workspace
+		if from.plan != "" {
+			ws, err = policy.LoadPlan(from.plan)
+		} else {
+			ws, err = policy.LoadState(from.state)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+			return nil, nil, exitError
+		}
+		ws.DestroyGuard = from.guard
+		return runEngine(ctx, ws, engine, nil, "", stderr)
+	}
+
+	ws, err := policy.LoadWorkspace(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return nil, nil, exitError
+	}
+
+	environments, err := ws.Environments()
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return nil, nil, exitError
+	}
+	if env != "" {
+		if !slices.Contains(environments, env) {
+			fmt.Fprintf(stderr, "berthcare-policy: no environment %q under %s/environments\n", env, dir)
+			return nil, nil, exitError
+		}
+		environments = []string{env}
+	}
+
+	return runEngine(ctx, ws, engine, environments, from.since, stderr)
+}
+
+// runEngine evaluates engine against the environments of ws and reports the
+// results, narrowed to the changes since the git revision since when given.
+func runEngine(ctx context.Context, ws *policy.Workspace, engine *policy.Engine, environments []string, since string, stderr io.Writer) (*policy.Workspace, *policy.Report, int) {
+	var (
+		results []policy.Result
+		changes *policy.ChangeSet
+		err     error
+	)
+	if since == "" {
+		results, err = engine.RunEnvironmentsContext(ctx, ws, environments)
+	} else {
+		results, changes, err = engine.RunSinceContext(ctx, ws, environments, since)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return nil, nil, exitError
+	}
+
+	if changes != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %d file(s) changed since %s, affecting %d block(s)\n", len(changes.Files), changes.Ref, len(changes.Affected))
+	}
+	return ws, policy.NewReport(ws, engine, results), exitClean
+}
+
+// registerPolicies adds the declarative rules in dir to the registry, for
+// the configuration at root, or for the one dir is the policies directory
+// of when root is empty. A directory named with --policies must exist; the
+// default one may not.
+func registerPolicies(dir string, root string, explicit bool, stderr io.Writer) bool {
+	if dir == "" {
+		return true
+	}
+	if explicit {
+		if _, err := os.Stat(dir); err != nil {
+			fmt.Fprintf(stderr, "berthcare-policy: --policies: %v\n", err)
+			return false
+		}
+	}
+	if root == "" {
+		root = filepath.Dir(dir)
+	}
+	environments, err := policy.ListEnvironments(root)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return false
+	}
+	if _, err := policy.RegisterPolicies(dir, environments); err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// fixCommand applies the fixes the rules offer for the violations in DIR,
+// or with --dry-run prints them as a diff.
+func fixCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fix", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		env      = flags.String("env", "", "fix environment-scoped rules for this environment only")
+		dryRun   = flags.Bool("dry-run", false, "print the fixes as a diff instead of writing them")
+		policies = flags.String("policies", "", "load declarative rules from `DIR` (default DIR/"+policy.PoliciesDir+")")
+		rules    stringsFlag
+	)
+	flags.Var(&rules, "rule", "fix only violations of this rule `ID` (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+
+	ws, report, code := evaluate("fix", flags, *env, target{}, *policies, rules, execution{}, stderr)
+	if report == nil {
+		return code
+	}
+	if len(ws.Diagnostics()) > 0 {
+		fmt.Fprintln(stderr, "berthcare-policy: not fixing while Terraform files fail to parse")
+		return exitError
+	}
+
+	var fixes []*policy.Fix
+	for _, v := range report.Violations() {
+		if v.Fix != nil {
+			fixes = append(fixes, v.Fix)
+		}
+	}
+	edits, err := policy.ApplyFixes(fixes)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	applied := 0
+	for _, edit := range edits {
+		applied += len(edit.Fixes)
+		if *dryRun {
+			diff, err := edit.UnifiedDiff()
+			if err != nil {
+				fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+				return exitError
+			}
+			fmt.Fprint(stdout, diff)
+			continue
+		}
+
+		info, err := os.Stat(edit.Path)
+		if err == nil {
+			err = os.WriteFile(edit.Path, edit.After, info.Mode())
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+			return exitError
+		}
+		for _, fix := range edit.Fixes {
+			fmt.Fprintf(stdout, "%s: %s\n", edit.Path, fix.Description)
+		}
+	}
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "%d fix(es) to apply in %d file(s)\n", applied, len(edits))
+		if applied > 0 {
+			return exitViolations
+		}
+		return exitClean
+	}
+	fmt.Fprintf(stdout, "applied %d fix(es) to %d file(s)\n", applied, len(edits))
+	return exitClean
+}
+
+// fmtCommand checks, or with --write fixes, the layout of the Terraform and
+// HCL files in DIR.
+func fmtCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("write", false, "rewrite the files that are not formatted instead of printing a diff")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "berthcare-policy: fmt takes at most one directory")
+		return exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	edits, err := policy.FormatFiles(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	for _, edit := range edits {
+		if !*write {
+			diff, err := edit.UnifiedDiff()
+			if err != nil {
+				fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+				return exitError
+			}
+			fmt.Fprint(stdout, diff)
+			continue
+		}
+
+		info, err := os.Stat(edit.Path)
+		if err == nil {
+			err = os.WriteFile(edit.Path, edit.After, info.Mode())
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(stdout, edit.Path)
+	}
+
+	if *write {
+		fmt.Fprintf(stdout, "formatted %d file(s)\n", len(edits))
+		return exitClean
+	}
+	fmt.Fprintf(stdout, "%d file(s) not formatted\n", len(edits))
+	if len(edits) > 0 {
+		return exitViolations
+	}
+	return exitClean
+}
+
+// validateCommand runs terraform validate offline against the modules in
+// DIR.
+func validateCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var validator policy.Validator
+	flags.StringVar(&validator.PluginDir, "plugin-dir", os.Getenv("TERRAFORM_PLUGIN_DIR"), "install providers from the mirror in `DIR`")
+	flags.StringVar(&validator.Terraform, "terraform", "terraform", "the terraform binary to run")
+	flags.DurationVar(&validator.Timeout, "timeout", 5*time.Minute, "fail a terraform command that runs longer than `DURATION`")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "berthcare-policy: validate takes at most one directory")
+		return exitError
+	}
+	if validator.PluginDir == "" {
+		fmt.Fprintln(stderr, "berthcare-policy: validate needs a provider mirror; pass --plugin-dir or set TERRAFORM_PLUGIN_DIR")
+		return exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	results, err := validator.Validate(ctx, dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	var errs, warnings int
+	for _, result := range results {
+		for _, v := range result.Violations {
+			fmt.Fprintln(stdout, v)
+			if v.Severity == policy.SeverityError {
+				errs++
+			} else {
+				warnings++
+			}
+		}
+	}
+	fmt.Fprintf(stdout, "validated %d module(s): %d error(s), %d warning(s)\n", len(results), errs, warnings)
+
+	if errs > 0 {
+		return exitViolations
+	}
+	return exitClean
+}
+
+// driftCommand compares the resources a state file records with those the
+// configuration in DIR declares.
+func driftCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("drift", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	state := flags.String("state", "", "compare the resources recorded in the state `FILE`")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if *state == "" || flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "berthcare-policy: drift takes --state FILE and at most one directory")
+		return exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	config, err := policy.LoadWorkspace(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+	if len(config.Diagnostics()) > 0 {
+		fmt.Fprintln(stderr, "berthcare-policy: not comparing state while Terraform files fail to parse")
+		return exitError
+	}
+	recorded, err := policy.LoadState(*state)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	drift := policy.CompareState(config, recorded)
+	for _, address := range drift.OnlyInState {
+		fmt.Fprintf(stdout, "only in state: %s\n", address)
+	}
+	for _, address := range drift.OnlyInCode {
+		fmt.Fprintf(stdout, "only in code:  %s\n", address)
+	}
+	fmt.Fprintf(stdout, "%d resource(s) in state, %d only in state, %d only in code\n", len(recorded.StateResources()), len(drift.OnlyInState), len(drift.OnlyInCode))
+
+	if len(drift.OnlyInState) > 0 || len(drift.OnlyInCode) > 0 {
+		return exitViolations
+	}
+	return exitClean
+}
+
+// mutateCommand applies security-relevant mutations to the configuration in
+// DIR one at a time and reports those no rule catches.
+func mutateCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mutate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		policies = flags.String("policies", "", "load declarative rules from `DIR` (default DIR/"+policy.PoliciesDir+")")
+		verbose  = flags.Bool("verbose", false, "also list the mutants that were caught and the rules that caught them")
+		rules    stringsFlag
+	)
+	flags.Var(&rules, "rule", "count only this rule `ID` as catching mutants (repeatable)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "berthcare-policy: mutate takes at most one directory")
+		return exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	if *policies == "" {
+		*policies = filepath.Join(dir, policy.PoliciesDir)
+	}
+	if !registerPolicies(*policies, dir, flagSet(flags, "policies"), stderr) {
+		return exitError
+	}
+	engine, err := policy.NewEngine(rules...)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	results, err := engine.RunMutations(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	killed := 0
+	for _, result := range results {
+		file := result.Mutant.Filename
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			file = filepath.ToSlash(rel)
+		}
+		switch {
+		case !result.Killed():
+			fmt.Fprintf(stdout, "survived: %s: %s\n", file, result.Mutant)
+		case *verbose:
+			fmt.Fprintf(stdout, "killed:   %s: %s (%s)\n", file, result.Mutant, strings.Join(result.KilledBy, ", "))
+		}
+		if result.Killed() {
+			killed++
+		}
+	}
+
+	score := 100.0
+	if len(results) > 0 {
+		score = 100 * float64(killed) / float64(len(results))
+	}
+	fmt.Fprintf(stdout, "mutation score: %d/%d killed (%.1f%%), %d survived\n", killed, len(results), score, len(results)-killed)
+
+	if killed < len(results) {
+		return exitViolations
+	}
+	return exitClean
+}
+
+// traceCommand writes the requirements traceability matrix.
+func traceCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("trace", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", "", "write the matrix to `FILE` instead of standard output")
+	policies := flags.String("policies", "", "also trace the declarative rules in `DIR`")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "berthcare-policy: trace takes at most one test directory")
+		return exitError
+	}
+	if !registerPolicies(*policies, "", true, stderr) {
+		return exitError
+	}
+	dir := "."
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+
+	annotations, err := policy.ScanAnnotations(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+	matrix := policy.BuildMatrix(policy.Rules(), annotations)
+
+	if err := writeMatrix(matrix, *output, stdout); err != nil {
+		fmt.Fprintf(stderr, "berthcare-policy: %v\n", err)
+		return exitError
+	}
+
+	if len(matrix.Untested) > 0 || len(matrix.Unvalidated) > 0 {
+		return exitViolations
+	}
+	return exitClean
+}
+
+// writeMatrix writes the matrix as Markdown to the file at path, or to stdout
+// when path is empty. The file is closed before returning so that a failed
+// write is reported rather than leaving a truncated matrix behind.
+func writeMatrix(matrix *policy.Matrix, path string, stdout io.Writer) error {
+	if path == "" {
+		return matrix.WriteMarkdown(stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := matrix.WriteMarkdown(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func listRules(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rules", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	policies := flags.String("policies", "", "also list the declarative rules in `DIR`")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+		return exitError
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "berthcare-policy: rules takes no arguments")
+		return exitError
+	}
+	if !registerPolicies(*policies, "", true, stderr) {
+		return exitError
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, rule := range policy.Rules() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rule.ID(), rule.Severity(), rule.Scope(), rule.Description())
+	}
+	if err := tw.Flush(); err != nil {
+		return exitError
+	}
+	return exitClean
+}
@@ -0,0 +1,678 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTerraform(t *testing.T, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0o644))
+	return dir
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	cases := []struct {
+		name string
+		tf   string
+		args []string
+		want int
+	}{
+		{
+			name: "clean",
+			tf:   "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = true\n}\n",
+			args: []string{"--rule", "RDS-001"},
+			want: exitClean,
+		},
+		{
+			name: "violations",
+			tf:   "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n}\n",
+			args: []string{"--rule", "RDS-001"},
+			want: exitViolations,
+		},
+		{
+			name: "parse error",
+			tf:   "resource \"aws_db_instance\" \"this\" {\n",
+			args: []string{"--rule", "RDS-001"},
+			want: exitError,
+		},
+		{
+			name: "unknown rule",
+			tf:   "",
+			args: []string{"--rule", "NOPE-001"},
+			want: exitError,
+		},
+		{
+			name: "unknown environment",
+			tf:   "",
+			args: []string{"--env", "qa"},
+			want: exitError,
+		},
+		{
+			name: "unknown format",
+			tf:   "",
+			args: []string{"--format", "yaml"},
+			want: exitError,
+		},
+		{
+			name: "one worker",
+			tf:   "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n}\n",
+			args: []string{"--rule", "RDS-001", "--workers", "1", "--rule-timeout", "1m"},
+			want: exitViolations,
+		},
+		{
+			name: "negative workers",
+			tf:   "",
+			args: []string{"--workers", "-1"},
+			want: exitError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeTerraform(t, tc.tf)
+
+			var stdout, stderr bytes.Buffer
+			code := run(append(append([]string{"check"}, tc.args...), dir), &stdout, &stderr)
+			require.Equal(t, tc.want, code, "stdout:\n%s\nstderr:\n%s", stdout.String(), stderr.String())
+		})
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n}\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "--rule", "RDS-001", "--format", "json", dir}, &stdout, &stderr)
+	require.Equal(t, exitViolations, code, stderr.String())
+
+	var report struct {
+		SchemaVersion int `json:"schema_version"`
+		Violations    []struct {
+			Rule     string `json:"rule"`
+			Resource string `json:"resource"`
+			Location struct {
+				StartLine int `json:"start_line"`
+			} `json:"location"`
+		} `json:"violations"`
+		Summary struct {
+			Violations int            `json:"violations"`
+			BySeverity map[string]int `json:"by_severity"`
+			ByRule     map[string]int `json:"by_rule"`
+		} `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.Equal(t, 1, report.SchemaVersion)
+	require.Len(t, report.Violations, 1)
+	require.Equal(t, "RDS-001", report.Violations[0].Rule)
+	require.Equal(t, "aws_db_instance.this", report.Violations[0].Resource)
+	require.Equal(t, 2, report.Violations[0].Location.StartLine)
+	require.Equal(t, 1, report.Summary.Violations)
+	require.Equal(t, map[string]int{"error": 1, "warning": 0, "info": 0}, report.Summary.BySeverity)
+	require.Equal(t, map[string]int{"RDS-001": 1}, report.Summary.ByRule)
+}
+
+func TestCheckMultipleFormats(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n  publicly_accessible = false\n}\n")
+	out := t.TempDir()
+	junitPath := filepath.Join(out, "policy.xml")
+	jsonPath := filepath.Join(out, "policy.json")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "--rule", "RDS-001", "--rule", "RDS-002", "--format", "github", "--format", "junit=" + junitPath, "--format", "json=" + jsonPath, dir}, &stdout, &stderr)
+	require.Equal(t, exitViolations, code, stderr.String())
+
+	require.Equal(t, "::error file="+filepath.ToSlash(filepath.Join(dir, "main.tf"))+",line=2,col=3,endLine=2,endColumn=28,title=RDS-001::aws_db_instance.this: storage_encrypted must be true%0ARemediation: set storage_encrypted = true\n", stdout.String())
+
+	junit, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string    `xml:"name,attr"`
+				Failure *struct{} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(junit, &suites))
+	// HCL-001 and POLICY-001 are always selected alongside the requested rules.
+	require.Equal(t, 4, suites.Tests)
+	require.Equal(t, 1, suites.Failures)
+	require.Len(t, suites.Suites, 1)
+	require.Equal(t, "workspace", suites.Suites[0].Name)
+
+	failed := map[string]bool{}
+	for _, tc := range suites.Suites[0].Cases {
+		failed[strings.SplitN(tc.Name, ":", 2)[0]] = tc.Failure != nil
+	}
+	require.Equal(t, map[string]bool{"HCL-001": false, "POLICY-001": false, "RDS-001": true, "RDS-002": false}, failed)
+
+	_, err = os.Stat(jsonPath)
+	require.NoError(t, err)
+}
+
+func TestCheckRejectsTwoStdoutFormats(t *testing.T) {
+	dir := writeTerraform(t, "")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "--format", "json", "--format", "sarif", dir}, &stdout, &stderr)
+	require.Equal(t, exitError, code)
+	require.Empty(t, stdout.String())
+}
+
+func TestCheckSARIF(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n}\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "--rule", "RDS-001", "--format", "sarif", dir}, &stdout, &stderr)
+	require.Equal(t, exitViolations, code, stderr.String())
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	first := log.Runs[0]
+	require.Len(t, first.Results, 1)
+	result := first.Results[0]
+	require.Equal(t, "RDS-001", result.RuleID)
+	require.Equal(t, "RDS-001", first.Tool.Driver.Rules[result.RuleIndex].ID)
+	require.Equal(t, "error", result.Level)
+	require.Len(t, result.Locations, 1)
+	require.Equal(t, "file://"+filepath.ToSlash(filepath.Join(dir, "main.tf")), result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Equal(t, 2, result.Locations[0].PhysicalLocation.Region.StartLine)
+	require.Equal(t, 3, result.Locations[0].PhysicalLocation.Region.StartColumn)
+}
+
+func TestCheckSuppressions(t *testing.T) {
+	cases := []struct {
+		name    string
+		comment string
+		want    int
+		problem string
+	}{
+		{
+			name:    "active",
+			comment: `# berthcare-policy:ignore RDS-001 reason="restored from an unencrypted snapshot" expires=2999-12-31`,
+			want:    exitClean,
+		},
+		{
+			name:    "expired",
+			comment: `# berthcare-policy:ignore RDS-001 reason="restored from an unencrypted snapshot" expires=2000-01-01`,
+			want:    exitViolations,
+			problem: "suppression expired on 2000-01-01",
+		},
+		{
+			name:    "unjustified",
+			comment: `# berthcare-policy:ignore RDS-001 expires=2999-12-31`,
+			want:    exitViolations,
+			problem: "suppression missing reason",
+		},
+		{
+			name:    "unknown rule",
+			comment: `# berthcare-policy:ignore RDS-999 reason="typo" expires=2999-12-31`,
+			want:    exitViolations,
+			problem: "suppression unknown rule RDS-999",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeTerraform(t, "resource \"aws_db_instance\" \"this\" {\n  "+tc.comment+"\n  storage_encrypted = false\n}\n")
+
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"check", "--rule", "RDS-001", "--format", "json", dir}, &stdout, &stderr)
+			require.Equal(t, tc.want, code, stderr.String())
+
+			var report struct {
+				Violations []struct {
+					Rule    string `json:"rule"`
+					Message string `json:"message"`
+				} `json:"violations"`
+				Suppressions []struct {
+					Rules      []string `json:"rules"`
+					Reason     string   `json:"reason"`
+					Suppressed int      `json:"suppressed"`
+				} `json:"suppressions"`
+				Summary struct {
+					Suppressed int `json:"suppressed"`
+				} `json:"summary"`
+			}
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+
+			if tc.problem == "" {
+				require.Empty(t, report.Violations)
+				require.Len(t, report.Suppressions, 1)
+				require.Equal(t, []string{"RDS-001"}, report.Suppressions[0].Rules)
+				require.Equal(t, 1, report.Suppressions[0].Suppressed)
+				require.Equal(t, 1, report.Summary.Suppressed)
+				return
+			}
+
+			require.Empty(t, report.Suppressions)
+			rules := map[string]string{}
+			for _, v := range report.Violations {
+				rules[v.Rule] = v.Message
+			}
+			require.Equal(t, tc.problem, rules["POLICY-001"])
+			require.Contains(t, rules, "RDS-001")
+		})
+	}
+}
+
+func TestCheckSARIFMarksSuppressedResults(t *testing.T) {
+	dir := writeTerraform(t, "# berthcare-policy:ignore RDS-001 reason=\"legacy snapshot\" expires=2999-12-31\nresource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n}\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"check", "--rule", "RDS-001", "--format", "sarif", dir}, &stdout, &stderr)
+	require.Equal(t, exitClean, code, stderr.String())
+
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleID       string `json:"ruleId"`
+				Suppressions []struct {
+					Kind          string `json:"kind"`
+					Justification string `json:"justification"`
+				} `json:"suppressions"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &log))
+	require.Len(t, log.Runs[0].Results, 1)
+	result := log.Runs[0].Results[0]
+	require.Equal(t, "RDS-001", result.RuleID)
+	require.Len(t, result.Suppressions, 1)
+	require.Equal(t, "inSource", result.Suppressions[0].Kind)
+	require.Equal(t, "legacy snapshot", result.Suppressions[0].Justification)
+}
+
+func TestBaseline(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"old\" {\n  storage_encrypted = false\n}\n")
+	path := filepath.Join(dir, "policy-baseline.json")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", dir}, &stdout, &stderr))
+
+	require.Equal(t, exitClean, run([]string{"baseline", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	baseline, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(baseline), `"resource": "aws_db_instance.old"`)
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"check", "--rule", "RDS-001", "--baseline", path, dir}, &stdout, &stderr), stdout.String())
+	require.Contains(t, stdout.String(), "0 violation(s), 0 suppressed, 1 baselined, 0 fixed since the baseline")
+
+	// A violation introduced after the baseline fails the check; moving the
+	// old one elsewhere in the file does not.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_db_instance\" \"new\" {\n  storage_encrypted = false\n}\n\nresource \"aws_db_instance\" \"old\" {\n  storage_encrypted = false\n}\n"), 0o644))
+	stdout.Reset()
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", "--baseline", path, dir}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "aws_db_instance.new")
+	require.NotContains(t, stdout.String(), "aws_db_instance.old")
+
+	// Pruning after fixing the old violation drops it without accepting the
+	// new one.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_db_instance\" \"new\" {\n  storage_encrypted = false\n}\n"), 0o644))
+	stderr.Reset()
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", "--baseline", path, dir}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "1 baselined violation(s) no longer occur")
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"baseline", "--prune", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	require.Equal(t, "wrote 0 violation(s) to "+path+" (was 1)\n", stdout.String())
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", "--baseline", path, dir}, &stdout, &stderr))
+}
+
+func TestBaselineKeepsRulesNotEvaluated(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = false\n  publicly_accessible = true\n}\n")
+	path := filepath.Join(dir, "policy-baseline.json")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitClean, run([]string{"baseline", "--rule", "RDS-001", "--rule", "RDS-002", dir}, &stdout, &stderr), stderr.String())
+
+	// Refreshing only RDS-001 leaves the RDS-002 entry alone.
+	require.Equal(t, exitClean, run([]string{"baseline", "--prune", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	require.Equal(t, exitClean, run([]string{"check", "--rule", "RDS-001", "--rule", "RDS-002", "--baseline", path, dir}, &stdout, &stderr))
+}
+
+func TestCheckRejectsUnknownSeverity(t *testing.T) {
+	dir := writeTerraform(t, "")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitError, run([]string{"check", "--fail-on", "critical", dir}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), `unknown severity "critical"`)
+}
+
+func TestCheckPlan(t *testing.T) {
+	plans := filepath.Join("..", "..", "testdata", "plans")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitClean, run([]string{"check", "--plan", filepath.Join(plans, "compliant.json")}, &stdout, &stderr), stdout.String()+stderr.String())
+
+	stdout.Reset()
+	require.Equal(t, exitViolations, run([]string{"check", "--plan", filepath.Join(plans, "noncompliant.json"), "--rule", "RDS-002"}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "[RDS-002] module.rds.aws_db_instance.this: publicly_accessible must be false")
+
+	require.Equal(t, exitError, run([]string{"check", "--plan", filepath.Join(plans, "compliant.json"), "--env", "dev"}, &stdout, &stderr))
+}
+
+func TestCheckPlanDestroyGuard(t *testing.T) {
+	plan := filepath.Join("..", "..", "testdata", "plans", "destroy.json")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"check", "--plan", plan, "--rule", "PLAN-001"}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "module.rds.aws_db_instance.this: plan replaces stateful aws_db_instance")
+	require.Contains(t, stdout.String(), "remediation: keep the existing object")
+
+	stdout.Reset()
+	args := []string{"check", "--plan", plan, "--rule", "PLAN-001", "--allow-destroy", "module.rds.aws_db_instance.this", "--allow-destroy", "module.s3.aws_s3_bucket.photos"}
+	require.Equal(t, exitClean, run(args, &stdout, &stderr), stdout.String())
+
+	stdout.Reset()
+	require.Equal(t, exitViolations, run(append(args, "--stateful-type", "aws_ecs_task_definition"), &stdout, &stderr))
+	require.Contains(t, stdout.String(), "plan deletes stateful aws_ecs_task_definition")
+
+	stdout.Reset()
+	require.Equal(t, exitViolations, run(append(args, "--max-destroy", "4"), &stdout, &stderr))
+	require.Contains(t, stdout.String(), "plan destroys 5 resources, more than the 4 allowed")
+
+	stderr.Reset()
+	require.Equal(t, exitError, run([]string{"check", "--allow-destroy", "module.rds.aws_db_instance.this", writeTerraform(t, "")}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "need --plan")
+}
+
+func TestCheckState(t *testing.T) {
+	state := filepath.Join("..", "..", "testdata", "state", "dev.tfstate")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"check", "--state", state}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "[S3-002] module.s3.aws_s3_bucket.exports: versioning.enabled must be true")
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"check", "--state", state, "--rule", "RDS-001"}, &stdout, &stderr), stdout.String())
+
+	require.Equal(t, exitError, run([]string{"check", "--state", state, "--plan", state}, &stdout, &stderr))
+}
+
+func TestDrift(t *testing.T) {
+	state := filepath.Join("..", "..", "testdata", "state", "dev.tfstate")
+	dir := writeTerraform(t, `
+resource "aws_acm_certificate" "this" {}
+
+resource "aws_route53_zone" "public" {}
+
+module "s3" {
+  source = "./modules/s3"
+}
+
+module "rds" {
+  source = "terraform-aws-modules/rds/aws"
+}
+`)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", "s3"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "modules", "s3", "main.tf"), []byte(`
+resource "aws_s3_bucket" "photos" {}
+resource "aws_s3_bucket" "exports" {}
+resource "aws_s3_bucket_public_access_block" "photos" {}
+resource "aws_s3_bucket_public_access_block" "exports" {}
+`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"drift", "--state", state, dir}, &stdout, &stderr), stderr.String())
+	// module.rds comes from the registry, so its resources cannot be
+	// checked, and the data source is not a managed resource.
+	require.Equal(t, "only in state: module.ecs.aws_lb_listener.http_redirect\n"+
+		"only in state: module.ecs.aws_lb_listener.https\n"+
+		"only in state: module.s3.aws_s3_bucket.legacy_uploads\n"+
+		"only in code:  aws_route53_zone.public\n"+
+		"10 resource(s) in state, 3 only in state, 1 only in code\n", stdout.String())
+
+	require.Equal(t, exitError, run([]string{"drift", dir}, &stdout, &stderr))
+}
+
+func TestTrace(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitClean, run([]string{"trace", filepath.Join("..", "..")}, &stdout, &stderr), stdout.String()+stderr.String())
+	require.Contains(t, stdout.String(), "| aws-dev-environment | 2: RDS Security Compliance | RDS-001, RDS-002, RDS-003, RDS-004 |")
+
+	// A test directory without annotations leaves every property untested.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty_test.go"), []byte("package tests\n"), 0o644))
+	stdout.Reset()
+	require.Equal(t, exitViolations, run([]string{"trace", dir}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "## Properties without a test\n\n- aws-dev-environment, Property 1: Regional Compliance\n")
+	require.Contains(t, stdout.String(), "- aws-dev-environment 1.1\n")
+}
+
+func TestCheckDeclarativeRules(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := filepath.Join("..", "..", "..")
+	require.Equal(t, exitClean, run([]string{"check", "--env", "staging", "--rule", "RDS-006", root}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "0 violation(s)")
+
+	// A directory's own policies/ is loaded unless --policies names another.
+	dir := writeTerraform(t, `
+resource "aws_dynamodb_table" "locks" {
+  name = "locks"
+}
+`)
+	policies := filepath.Join(dir, "policies")
+	require.NoError(t, os.MkdirAll(policies, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(policies, "dynamodb.hcl"), []byte(`
+rule "CUSTOM-001" {
+  description   = "DynamoDB tables must enable point-in-time recovery"
+  severity      = "warning"
+  resource_type = "aws_dynamodb_table"
+  attribute     = "point_in_time_recovery.enabled"
+  operator      = "equals"
+  value         = true
+}
+`), 0o644))
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"check", "--rule", "CUSTOM-001", dir}, &stdout, &stderr), stderr.String())
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "CUSTOM-001", "--fail-on", "warning", dir}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "[CUSTOM-001] aws_dynamodb_table.locks: aws_dynamodb_table missing point_in_time_recovery.enabled")
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"rules", "--policies", policies}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "CUSTOM-001")
+
+	require.Equal(t, exitError, run([]string{"check", "--policies", filepath.Join(dir, "missing"), dir}, &stdout, &stderr))
+
+	// Rules may name only the environments the directory has.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "environments", "staging"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(policies, "rds.hcl"), []byte(`
+rule "CUSTOM-002" {
+  description   = "RDS instances must enable deletion protection"
+  severity      = "error"
+  resource_type = "aws_db_instance"
+  attribute     = "deletion_protection"
+  operator      = "equals"
+  value         = true
+  environments  = ["staging", "prod"]
+}
+`), 0o644))
+	stderr.Reset()
+	require.Equal(t, exitError, run([]string{"check", dir}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), `rule CUSTOM-002: environment "prod" has no directory under environments/`)
+}
+
+func TestFix(t *testing.T) {
+	dir := writeTerraform(t, `
+resource "aws_db_instance" "main" {
+  storage_encrypted = false # flipped during the migration
+}
+`)
+	path := filepath.Join(dir, "main.tf")
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"fix", "--dry-run", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "-  storage_encrypted = false # flipped during the migration\n+  storage_encrypted = true # flipped during the migration\n")
+	require.Contains(t, stdout.String(), "1 fix(es) to apply in 1 file(s)")
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(before), string(after), "--dry-run must not write")
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"fix", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "set storage_encrypted = true")
+	require.Equal(t, exitClean, run([]string{"check", "--rule", "RDS-001", dir}, &stdout, &stderr))
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"fix", "--dry-run", "--rule", "RDS-001", dir}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "0 fix(es) to apply in 0 file(s)")
+}
+
+// TestFixKeepsUntouchedLayout requires fix to format only the blocks it
+// edits, leaving the layout of the rest of the file to fmt.
+func TestFixKeepsUntouchedLayout(t *testing.T) {
+	untouched := "resource \"aws_s3_bucket\" \"logs\" {\n    bucket = \"logs\"\n  force_destroy   =   false\n}\n"
+	dir := writeTerraform(t, untouched+"\nresource \"aws_db_instance\" \"main\" {\n  storage_encrypted = false\n  engine = \"postgres\"\n}\n")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitClean, run([]string{"fix", "--rule", "RDS-001", dir}, &stdout, &stderr), stderr.String())
+	after, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, untouched+"\nresource \"aws_db_instance\" \"main\" {\n  storage_encrypted = true\n  engine            = \"postgres\"\n}\n", string(after))
+}
+
+func TestFmt(t *testing.T) {
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"main\" {\n  storage_encrypted = true\n  publicly_accessible = false\n}\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dev.tfvars"), []byte("region    =   \"ca-central-1\"\n"), 0o644))
+	path := filepath.Join(dir, "main.tf")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"fmt", dir}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "--- a/"+filepath.ToSlash(strings.TrimPrefix(path, "/")))
+	require.Contains(t, stdout.String(), "-  storage_encrypted = true\n+  storage_encrypted   = true\n")
+	require.Contains(t, stdout.String(), "-region    =   \"ca-central-1\"\n+region = \"ca-central-1\"\n")
+	require.Contains(t, stdout.String(), "2 file(s) not formatted")
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"fmt", "--write", dir}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "formatted 2 file(s)")
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "resource \"aws_db_instance\" \"main\" {\n  storage_encrypted   = true\n  publicly_accessible = false\n}\n", string(after))
+
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"fmt", dir}, &stdout, &stderr), stderr.String())
+	require.Contains(t, stdout.String(), "0 file(s) not formatted")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.tf"), []byte("resource \"aws_s3_bucket\" {\n"), 0o644))
+	stderr.Reset()
+	require.Equal(t, exitError, run([]string{"fmt", dir}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "broken.tf")
+}
+
+func TestValidateNeedsProviderMirror(t *testing.T) {
+	t.Setenv("TERRAFORM_PLUGIN_DIR", "")
+	dir := writeTerraform(t, "")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitError, run([]string{"validate", dir}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), "pass --plugin-dir or set TERRAFORM_PLUGIN_DIR")
+}
+
+func TestMutate(t *testing.T) {
+	dir := writeTerraform(t, `
+resource "aws_db_instance" "main" {
+  engine                  = "postgres"
+  storage_encrypted       = true
+  publicly_accessible     = false
+  backup_retention_period = 7
+}
+`)
+
+	// Without environments the backup retention rule never runs, so nothing
+	// catches the shortened retention.
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitViolations, run([]string{"mutate", "--verbose", dir}, &stdout, &stderr), stderr.String())
+	require.Equal(t, "killed:   main.tf: public-database aws_db_instance.main: set publicly_accessible = true (RDS-002)\n"+
+		"killed:   main.tf: unencrypted-database aws_db_instance.main: set storage_encrypted = false (RDS-001)\n"+
+		"survived: main.tf: short-backup-retention aws_db_instance.main: set backup_retention_period = 1\n"+
+		"mutation score: 2/3 killed (66.7%), 1 survived\n", stdout.String())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "environments", "dev"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "environments", "dev", "terraform.tfvars"), nil, 0o644))
+	stdout.Reset()
+	require.Equal(t, exitClean, run([]string{"mutate", dir}, &stdout, &stderr), stderr.String())
+	require.Equal(t, "mutation score: 3/3 killed (100.0%), 0 survived\n", stdout.String())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource {"), 0o644))
+	require.Equal(t, exitError, run([]string{"mutate", dir}, &stdout, &stderr))
+}
+
+func TestCheckSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := writeTerraform(t, "resource \"aws_db_instance\" \"old\" {\n  storage_encrypted = false\n}\n")
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, "%s", out)
+	}
+	git("init", "--quiet")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "base")
+
+	// The existing violation is not the change's.
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitClean, run([]string{"check", "--rule", "RDS-001", "--since", "HEAD", dir}, &stdout, &stderr), stdout.String())
+	require.Contains(t, stderr.String(), "0 file(s) changed since HEAD, affecting 0 block(s)")
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", dir}, &stdout, &stderr))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.tf"), []byte("resource \"aws_db_instance\" \"new\" {\n  storage_encrypted = false\n}\n"), 0o644))
+	stdout.Reset()
+	require.Equal(t, exitViolations, run([]string{"check", "--rule", "RDS-001", "--since", "HEAD", dir}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "aws_db_instance.new")
+	require.NotContains(t, stdout.String(), "aws_db_instance.old")
+
+	require.Equal(t, exitError, run([]string{"check", "--since", "no-such-branch", dir}, &stdout, &stderr))
+	require.Equal(t, exitError, run([]string{"check", "--since", "HEAD", "--plan", "plan.json"}, &stdout, &stderr))
+}
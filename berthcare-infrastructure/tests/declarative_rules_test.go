@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeclarativeRules compiles rules declared in HCL and evaluates them
+// like the rules written in Go.
+func TestDeclarativeRules(t *testing.T) {
+	t.Run("repository policies are registered", func(t *testing.T) {
+		loadWorkspace(t, "")
+
+		rule, ok := policy.Lookup("RDS-006")
+		require.True(t, ok, "expected RDS-006 from %s to be registered", policy.PoliciesDir)
+		require.Equal(t, policy.ScopeEnvironment, rule.Scope())
+		require.True(t, policy.AppliesTo(rule, "staging"))
+		require.False(t, policy.AppliesTo(rule, "dev"))
+		require.False(t, policy.SupportsPlan(rule))
+
+		engine, err := policy.NewEngine("RDS-006")
+		require.NoError(t, err)
+		require.Empty(t, engine.Run(loadWorkspace(t, "dev")), "RDS-006 does not apply to dev")
+
+		require.Empty(t, engine.Run(loadWorkspace(t, "staging")), "staging keeps the module's deletion protection")
+	})
+
+	t.Run("operators", func(t *testing.T) {
+		rules, err := policy.LoadPolicies("testdata/policies", []string{"dev"})
+		require.NoError(t, err)
+		require.Len(t, rules, 6)
+
+		ws := loadWorkspace(t, "dev")
+		found := map[string][]string{}
+		for _, rule := range rules {
+			for _, v := range rule.Evaluate(ws) {
+				require.Equal(t, rule.Severity(), v.Severity)
+				found[v.RuleID] = append(found[v.RuleID], v.Resource+": "+v.Message)
+			}
+		}
+
+		require.Equal(t, map[string][]string{
+			"FIXTURE-001": {"module.rds.aws_db_instance.this: backup_retention_period is 7 (must be at least 14)"},
+			"FIXTURE-003": {`module.rds.aws_db_instance.this: engine is "postgres" (must be one of ["mysql", "mariadb"])`},
+			"FIXTURE-004": {
+				`module.ecs.aws_security_group.alb: egress.protocol must not equal "-1"`,
+				`module.ecs.aws_security_group.tasks: egress.protocol must not equal "-1"`,
+				`module.rds.aws_security_group.db: egress.protocol must not equal "-1"`,
+			},
+			"FIXTURE-005": {"module.rds.aws_db_instance.this: aws_db_instance missing iops"},
+			"FIXTURE-006": {"module.rds.aws_db_instance.this: skip_final_snapshot must not be set"},
+		}, found)
+
+		byID := map[string]policy.Rule{}
+		for _, rule := range rules {
+			byID[rule.ID()] = rule
+		}
+		require.Equal(t, policy.ScopeWorkspace, byID["FIXTURE-003"].Scope())
+		require.True(t, policy.SupportsPlan(byID["FIXTURE-003"]))
+		require.True(t, policy.SupportsState(byID["FIXTURE-003"]))
+		require.Equal(t, []policy.Trace{{
+			Property:     policy.Property{Feature: "aws-dev-environment", Number: 2, Name: "RDS Security Compliance"},
+			Requirements: []string{"2.5"},
+		}}, policy.Traces(byID["FIXTURE-006"]))
+	})
+
+	t.Run("invalid policies are rejected", func(t *testing.T) {
+		cases := map[string]struct {
+			body string
+			err  string
+		}{
+			"unknown operator": {
+				body: `operator = "contains"` + "\n" + `value = "x"`,
+				err:  `unknown operator "contains"`,
+			},
+			"missing value": {
+				body: `operator = "equals"`,
+				err:  "operator equals needs a value",
+			},
+			"value for present": {
+				body: `operator = "present"` + "\n" + `value = true`,
+				err:  "operator present takes no value",
+			},
+			"non-constant value": {
+				body: `operator = "equals"` + "\n" + `value = var.expected`,
+				err:  "value must be a constant",
+			},
+			"unknown environment": {
+				body: `operator = "present"` + "\n" + `environments = ["staging", "prod"]`,
+				err:  `environment "prod" has no directory under environments/`,
+			},
+			"bad pattern": {
+				body: `operator = "matches"` + "\n" + `value = "("`,
+				err:  "missing closing )",
+			},
+			"unknown severity": {
+				body: `operator = "present"`,
+				err:  "unknown severity",
+			},
+		}
+
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				severity := "error"
+				if name == "unknown severity" {
+					severity = "fatal"
+				}
+				dir := t.TempDir()
+				content := `rule "BAD-001" {
+description   = "bad"
+severity      = "` + severity + `"
+resource_type = "aws_db_instance"
+attribute     = "engine"
+` + tc.body + "\n}\n"
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.hcl"), []byte(content), 0o644))
+
+				_, err := policy.LoadPolicies(dir, []string{"dev", "staging"})
+				require.ErrorContains(t, err, tc.err)
+				require.ErrorContains(t, err, "bad.hcl:1: rule BAD-001")
+			})
+		}
+	})
+
+	t.Run("a policy may not take the ID of a Go rule", func(t *testing.T) {
+		dir := t.TempDir()
+		content := `rule "RDS-001" {
+description   = "shadow"
+severity      = "error"
+resource_type = "aws_db_instance"
+attribute     = "storage_encrypted"
+operator      = "equals"
+value         = false
+}
+`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "shadow.hcl"), []byte(content), 0o644))
+
+		_, err := policy.RegisterPolicies(dir, nil)
+		require.ErrorContains(t, err, "rule RDS-001 is already registered")
+	})
+
+	t.Run("a missing directory declares no rules", func(t *testing.T) {
+		rules, err := policy.LoadPolicies(filepath.Join(t.TempDir(), policy.PoliciesDir), nil)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+	})
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnvironmentMatrix runs every workspace-scoped rule once and every
+// environment-scoped rule against each directory under environments/. It
+// fails on error-severity violations not recorded in policy-baseline.json,
+// and on baseline entries that have been fixed so that the baseline only
+// shrinks.
+//
+// **Feature: aws-dev-environment, Property 1: Regional Compliance**
+// **Validates: Requirements 1.1, 1.2**
+// **Feature: aws-dev-environment, Property 2: RDS Security Compliance**
+// **Validates: Requirements 2.2, 2.3, 2.4, 2.5**
+// **Feature: aws-dev-environment, Property 3: S3 Security Compliance**
+// **Validates: Requirements 3.1, 3.2, 3.3, 3.4**
+// **Feature: aws-dev-environment, Property 4: ECS Private Subnet Placement**
+// **Validates: Requirements 4.5**
+// **Feature: aws-dev-environment, Property 5: State Backend Configuration**
+// **Validates: Requirements 6.1, 6.2, 6.3**
+// **Feature: aws-dev-environment, Property 6: Resource Tagging Compliance**
+// **Validates: Requirements 8.1, 8.2, 8.3**
+// **Feature: aws-staging-environment, Property 1: Regional Compliance**
+// **Validates: Requirements 1.1, 1.2, 1.3**
+// **Feature: aws-staging-environment, Property 2: RDS Security Compliance**
+// **Validates: Requirements 2.1, 2.2, 2.3, 2.4, 2.5**
+// **Feature: aws-staging-environment, Property 3: S3 Security Compliance**
+// **Validates: Requirements 3.1, 3.2, 3.3, 3.4, 3.5**
+// **Feature: aws-staging-environment, Property 4: ALB HTTPS Configuration**
+// **Validates: Requirements 4.2, 4.3, 4.4, 6.3, 6.4**
+// **Feature: aws-staging-environment, Property 5: ECS Private Subnet Placement**
+// **Validates: Requirements 4.5**
+// **Feature: aws-staging-environment, Property 6: DNS Configuration**
+// **Validates: Requirements 5.1, 5.2, 5.4**
+// **Feature: aws-staging-environment, Property 7: ACM Certificate Configuration**
+// **Validates: Requirements 6.1, 6.2**
+// **Feature: aws-staging-environment, Property 8: State Backend Configuration**
+// **Validates: Requirements 7.1, 7.2, 7.3**
+// **Feature: aws-staging-environment, Property 9: Resource Tagging Compliance**
+// **Validates: Requirements 9.1, 9.2, 9.3**
+func TestEnvironmentMatrix(t *testing.T) {
+	ws := loadWorkspace(t, "")
+
+	engine, err := policy.NewEngine()
+	require.NoError(t, err)
+
+	results, err := engine.RunMatrix(ws)
+	require.NoError(t, err)
+
+	baseline, err := policy.ReadBaseline(filepath.Join(repoRoot, policy.BaselineFile))
+	require.NoError(t, err)
+	report := policy.NewReport(ws, engine, results)
+	report.ApplyBaseline(baseline)
+
+	environments, err := ws.Environments()
+	require.NoError(t, err)
+	require.NotEmpty(t, environments, "expected at least one directory under environments/")
+
+	byEnvironment := map[string][]policy.Result{}
+	for _, result := range report.Results {
+		byEnvironment[result.Environment] = append(byEnvironment[result.Environment], result)
+	}
+
+	for _, env := range append([]string{""}, environments...) {
+		name := env
+		if name == "" {
+			name = "workspace"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			require.NotEmpty(t, byEnvironment[env], "expected rules to run for %s", name)
+
+			for _, result := range byEnvironment[env] {
+				t.Run(result.Rule.ID(), func(t *testing.T) {
+					var lines []string
+					for _, v := range result.Violations {
+						if !v.Severity.AtLeast(policy.SeverityError) {
+							t.Log(v.String())
+							continue
+						}
+						lines = append(lines, v.String())
+					}
+					if len(lines) > 0 {
+						t.Fatalf("%s: %s\n%s", result.Rule.ID(), result.Rule.Description(), strings.Join(lines, "\n"))
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("baseline", func(t *testing.T) {
+		for _, entry := range report.Fixed {
+			t.Errorf("%s %s %s: %q no longer occurs", entry.Rule, entry.Environment, entry.File, entry.Message)
+		}
+		if len(report.Fixed) > 0 {
+			t.Log("run go run ./cmd/berthcare-policy baseline --prune .. to drop fixed violations from the baseline")
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// fuzzSeeds are inputs the validators have crashed on, or come close to:
+// null and computed values where they expect constants, and values of the
+// wrong type.
+var fuzzSeeds = []string{
+	`resource "aws_db_subnet_group" "main" {
+  subnet_ids = null
+}`,
+	`resource "aws_autoscaling_group" "ecs" {
+  vpc_zone_identifier = tolist(["subnet-1", null])
+}`,
+	`resource "aws_security_group" "db" {
+  ingress {
+    from_port       = 5432
+    to_port         = 5432
+    protocol        = "tcp"
+    security_groups = ["sg-1", true ? null : "sg-2"]
+    cidr_blocks     = null
+  }
+}`,
+	`resource "aws_lb_listener" "https" {
+  port       = 443
+  protocol   = true ? null : "HTTPS"
+  ssl_policy = false ? "x" : null
+}`,
+	`resource "aws_s3_bucket" "photos" {
+  versioning {
+    enabled = true ? null : true
+  }
+}`,
+	`variable "availability_zones" {}
+variable "task_role_arn" {}
+variable "domain_name" {}
+variable "route53_zone_id" {}
+
+provider "aws" {
+  region = true ? null : "ca-central-1"
+  default_tags {
+    tags = {
+      Region      = null
+      Environment = var.task_role_arn
+    }
+  }
+}
+
+terraform {
+  backend "s3" {
+    region  = null
+    encrypt = true ? null : true
+  }
+}
+
+module "network" {
+  source = true ? null : "./modules/network"
+  count  = null
+}
+
+resource "aws_subnet" "private" {
+  count                   = length(var.availability_zones)
+  availability_zone       = var.availability_zones[count.index]
+  map_public_ip_on_launch = true ? null : false
+}
+
+resource "aws_db_instance" "this" {
+  publicly_accessible     = true ? null : false
+  storage_encrypted       = null
+  backup_retention_period = true ? null : 7
+}
+
+resource "aws_acm_certificate" "this" {
+  domain_name       = var.domain_name
+  validation_method = true ? null : "DNS"
+  lifecycle {
+    create_before_destroy = true ? null : true
+  }
+}
+
+resource "aws_route53_record" "alias" {
+  zone_id = var.route53_zone_id
+  name    = var.domain_name
+  alias {
+    evaluate_target_health = true ? null : true
+  }
+}
+
+data "aws_iam_policy_document" "photos" {
+  statement {
+    principals {
+      identifiers = [var.task_role_arn, null]
+    }
+  }
+}`,
+}
+
+// fuzzTFVars and fuzzBackend are the dev environment's files for the seeds
+// that are not the repository's own.
+const (
+	fuzzTFVars = `availability_zones = ["ca-central-1a", null]
+task_role_arn      = null
+domain_name        = "dev.berthcare.ca"
+route53_zone_id    = 42
+`
+	fuzzBackend = `bucket  = null
+encrypt = "yes"
+`
+)
+
+// addRepositorySeeds adds every Terraform file of the repository and of the
+// rule fixtures to the corpus of f, each with the environment files of its
+// tree's dev environment.
+func addRepositorySeeds(f *testing.F, add func(config []byte, tfvars []byte, backend []byte)) {
+	f.Helper()
+
+	for _, root := range []string{repoRoot, ruleFixturesDir} {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && (d.Name() == ".terraform" || d.Name() == ".git" || root == repoRoot && d.Name() == "tests") {
+				return filepath.SkipDir
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".tf") {
+				return nil
+			}
+
+			config, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			// The dev environment of the nearest tree, if any.
+			tfvars, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "environments", "dev", "terraform.tfvars"))
+			backend, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "environments", "dev", "backend.hcl"))
+			add(config, tfvars, backend)
+			return nil
+		})
+		require.NoError(f, err)
+	}
+
+	for _, seed := range fuzzSeeds {
+		add([]byte(seed), []byte(fuzzTFVars), []byte(fuzzBackend))
+	}
+}
+
+// writeFuzzWorkspace writes a Terraform root with the given main.tf and dev
+// environment files and loads it.
+func writeFuzzWorkspace(t *testing.T, config []byte, tfvars []byte, backend []byte) *policy.Workspace {
+	t.Helper()
+
+	dir := t.TempDir()
+	env := filepath.Join(dir, "environments", "dev")
+	require.NoError(t, os.MkdirAll(env, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), config, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(env, "terraform.tfvars"), tfvars, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(env, "backend.hcl"), backend, 0o644))
+
+	ws, err := policy.LoadWorkspace(dir)
+	require.NoError(t, err)
+	return ws
+}
+
+// FuzzRules evaluates every rule against generated configurations and
+// environment files. Whatever the input, a rule must report a violation
+// rather than panic.
+func FuzzRules(f *testing.F) {
+	environments, err := policy.ListEnvironments(repoRoot)
+	require.NoError(f, err)
+	_, err = policy.RegisterPolicies(filepath.Join(repoRoot, policy.PoliciesDir), environments)
+	require.NoError(f, err)
+
+	addRepositorySeeds(f, func(config []byte, tfvars []byte, backend []byte) {
+		f.Add(config, tfvars, backend)
+	})
+
+	engine, err := policy.NewEngine()
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, config []byte, tfvars []byte, backend []byte) {
+		ws := writeFuzzWorkspace(t, config, tfvars, backend)
+		_, err := engine.RunMatrix(ws)
+		require.NoError(t, err)
+	})
+}
+
+// FuzzDeclarativeRules compiles generated policy files and evaluates the
+// rules they declare against generated configurations.
+func FuzzDeclarativeRules(f *testing.F) {
+	policies, err := filepath.Glob(filepath.Join(repoRoot, policy.PoliciesDir, "*.hcl"))
+	require.NoError(f, err)
+	policies = append(policies, filepath.Join("testdata", "policies", "fixture.hcl"))
+
+	var configs [][]byte
+	addRepositorySeeds(f, func(config []byte, _ []byte, _ []byte) {
+		configs = append(configs, config)
+	})
+	for _, path := range policies {
+		content, err := os.ReadFile(path)
+		require.NoError(f, err)
+		for _, config := range configs {
+			f.Add(content, config)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, policyFile []byte, config []byte) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "rules.hcl"), policyFile, 0o644))
+		rules, err := policy.LoadPolicies(dir, []string{"dev"})
+		if err != nil {
+			return
+		}
+
+		ws := writeFuzzWorkspace(t, config, nil, nil)
+		for _, rule := range rules {
+			rule.Evaluate(ws)
+			rule.Evaluate(ws.WithEnvironment("dev"))
+		}
+	})
+}
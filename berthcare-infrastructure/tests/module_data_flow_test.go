@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/require"
+)
+
+// **Feature: aws-dev-environment, Property 4: ECS Private Subnet Placement**
+// **Feature: aws-dev-environment, Property 2: RDS Security Compliance**
+// **Validates: Requirements 2.4, 4.5**
+func TestModuleDataFlow(t *testing.T) {
+	t.Run("Feature: aws-dev-environment, Property 4: ECS Private Subnet Placement", func(t *testing.T) {
+		ws := loadWorkspace(t, "")
+
+		cases := []struct {
+			module    string
+			address   string
+			attribute string
+			origin    string
+		}{
+			{"modules/ecs", "aws_autoscaling_group.ecs", "vpc_zone_identifier", "module.vpc.aws_subnet.private"},
+			{"modules/ecs", "aws_lb.this", "subnets", "module.vpc.aws_subnet.public"},
+			{"modules/rds", "aws_db_subnet_group.this", "subnet_ids", "module.vpc.aws_subnet.private"},
+			{"modules/ecs", "aws_lb_listener.https", "certificate_arn", "aws_acm_certificate.this"},
+		}
+
+		for _, tc := range cases {
+			block := findBlock(t, ws, tc.module, tc.address)
+			attr, ok := block.Body.Attributes[tc.attribute]
+			require.True(t, ok, "%s.%s not set", tc.address, tc.attribute)
+
+			instances := ws.Instances(tc.module)
+			require.Len(t, instances, 1, "expected %s to be called once from the root module", tc.module)
+
+			require.Contains(t, resourceOrigins(ws.TraceOrigins(instances[0], attr.Expr)), tc.origin, "%s.%s", tc.address, tc.attribute)
+		}
+	})
+
+	t.Run("Feature: aws-dev-environment, Property 2: RDS Security Compliance", func(t *testing.T) {
+		ws := loadWorkspace(t, "")
+
+		block := findBlock(t, ws, "modules/rds", "aws_security_group.db")
+		var ingress *hclsyntax.Block
+		for _, nested := range block.Body.Blocks {
+			if nested.Type == "ingress" {
+				ingress = nested
+			}
+		}
+		require.NotNil(t, ingress, "expected an ingress block on aws_security_group.db")
+
+		attr, ok := ingress.Body.Attributes["security_groups"]
+		require.True(t, ok)
+
+		origins := resourceOrigins(ws.TraceOrigins(ws.Instances("modules/rds")[0], attr.Expr))
+		require.Equal(t, []string{"module.ecs.aws_security_group.tasks"}, origins)
+	})
+}
+
+func findBlock(t *testing.T, ws *policy.Workspace, module string, address string) *policy.Block {
+	t.Helper()
+
+	for _, block := range ws.Lookup(address) {
+		if block.Module == module {
+			return block
+		}
+	}
+
+	t.Fatalf("expected %s in %s", address, module)
+	return nil
+}
+
+func resourceOrigins(origins []policy.Origin) []string {
+	var addresses []string
+	for _, origin := range origins {
+		if !origin.IsVariable() {
+			addresses = append(addresses, origin.String())
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMutation mutates the repository's Terraform in security-relevant ways
+// and requires every mutant to be caught by a rule, apart from the known
+// gaps listed below. Remove a gap from the list once a rule closes it.
+func TestMutation(t *testing.T) {
+	loadWorkspace(t, "")
+
+	engine, err := policy.NewEngine()
+	require.NoError(t, err)
+	results, err := engine.RunMutations(repoRoot)
+	require.NoError(t, err)
+
+	operators := map[string]bool{}
+	killedBy := map[string][]string{}
+	var survived []string
+	for _, result := range results {
+		operators[result.Mutant.Operator] = true
+		killedBy[result.Mutant.String()] = result.KilledBy
+		if !result.Killed() {
+			survived = append(survived, result.Mutant.String())
+		}
+	}
+
+	require.Equal(t, []string{
+		// DNS-001 checks the public alias for the environment's domain, not
+		// the private zone's record.
+		"unhealthy-alias aws_route53_record.alb_alias: set alias.evaluate_target_health = false",
+		// BACKEND-001 checks the environments' backend.hcl, not the backend
+		// block itself.
+		"unencrypted-state-backend terraform: set backend.s3.encrypt = false",
+	}, survived)
+
+	require.Equal(t, []string{"RDS-002"}, killedBy["public-database aws_db_instance.this: set publicly_accessible = true"])
+	require.Equal(t, []string{"ALB-001"}, killedBy[`weak-tls-policy aws_lb_listener.https: set ssl_policy = "ELBSecurityPolicy-2016-08"`])
+	require.Equal(t, []string{"RDS-005"}, killedBy[`open-postgres-ingress aws_security_group.db: set ingress.cidr_blocks = ["0.0.0.0/0"] on port 5432`])
+	require.Equal(t, []string{"REGION-001", "REGION-002"}, killedBy[`foreign-provider-region provider.aws: set region = "us-east-1"`])
+	require.Equal(t, []string{"ECS-001", "RDS-004"}, killedBy["public-private-subnet aws_subnet.private: set map_public_ip_on_launch = true"])
+
+	// Every operator finds something to mutate in the repository.
+	require.Len(t, operators, 16)
+}
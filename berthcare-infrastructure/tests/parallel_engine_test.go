@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParallelEngine evaluates the repository's rules concurrently and
+// requires the same results as evaluating them one at a time.
+func TestParallelEngine(t *testing.T) {
+	ws := loadWorkspace(t, "")
+
+	t.Run("results do not depend on the number of workers", func(t *testing.T) {
+		sequential, err := policy.NewEngine()
+		require.NoError(t, err)
+		sequential.Workers = 1
+		want := matrixFindings(t, sequential, ws)
+		require.NotEmpty(t, want)
+
+		for _, workers := range []int{0, 4, 64} {
+			engine, err := policy.NewEngine()
+			require.NoError(t, err)
+			engine.Workers = workers
+			for range 5 {
+				require.Equal(t, want, matrixFindings(t, engine, ws), "%d workers", workers)
+			}
+		}
+	})
+
+	t.Run("a cancelled run returns the context's error", func(t *testing.T) {
+		engine, err := policy.NewEngine()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err := engine.RunMatrixContext(ctx, ws)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, results)
+	})
+
+	t.Run("rules that run out of time fail", func(t *testing.T) {
+		engine, err := policy.NewEngine("RDS-001")
+		require.NoError(t, err)
+		engine.RuleTimeout = time.Nanosecond
+
+		results, err := engine.RunMatrixContext(context.Background(), ws)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		for _, result := range results {
+			require.Len(t, result.Violations, 1)
+			v := result.Violations[0]
+			require.Equal(t, result.Rule.ID(), v.RuleID)
+			require.Equal(t, policy.SeverityError, v.Severity)
+			require.Equal(t, result.Rule.ID()+" did not finish within 1ns", v.Message)
+		}
+	})
+}
+
+// matrixFindings evaluates engine against every environment of ws and
+// returns each result's rule, environment and violations in order.
+func matrixFindings(t *testing.T, engine *policy.Engine, ws *policy.Workspace) []string {
+	t.Helper()
+
+	results, err := engine.RunMatrix(ws)
+	require.NoError(t, err)
+
+	var found []string
+	for _, result := range results {
+		found = append(found, result.Rule.ID()+" "+result.Environment)
+		for _, v := range result.Violations {
+			found = append(found, "  "+v.String())
+		}
+	}
+	return found
+}
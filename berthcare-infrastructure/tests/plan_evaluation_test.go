@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const planFixtures = "testdata/plans"
+
+func loadPlan(t *testing.T, name string) *policy.Workspace {
+	t.Helper()
+
+	ws, err := policy.LoadPlan(filepath.Join(planFixtures, name))
+	require.NoError(t, err)
+	return ws
+}
+
+// TestPlanEvaluation runs the rules that support plans against plans written
+// by terraform show -json, where values computed at plan time, for_each
+// instances and values passed between modules are resolved.
+func TestPlanEvaluation(t *testing.T) {
+	engine, err := policy.NewEngine()
+	require.NoError(t, err)
+
+	t.Run("compliant plan passes", func(t *testing.T) {
+		ws := loadPlan(t, "compliant.json")
+
+		results := engine.RunEnvironments(ws, nil)
+		var evaluated []string
+		for _, result := range results {
+			evaluated = append(evaluated, result.Rule.ID())
+			for _, v := range result.Violations {
+				t.Errorf("unexpected violation: %s", v)
+			}
+		}
+		sort.Strings(evaluated)
+		require.Equal(t, []string{"ALB-001", "ALB-002", "PLAN-001", "RDS-001", "RDS-002", "REGION-001", "S3-001", "S3-002", "S3-003", "TAG-001"}, evaluated)
+	})
+
+	t.Run("noncompliant plan fails", func(t *testing.T) {
+		ws := loadPlan(t, "noncompliant.json")
+
+		found := map[string][]string{}
+		for _, v := range engine.Run(ws) {
+			require.Equal(t, filepath.Join(planFixtures, "noncompliant.json"), v.Range.Filename)
+			found[v.RuleID] = append(found[v.RuleID], v.Resource)
+		}
+		require.Equal(t, map[string][]string{
+			"ALB-001": {"module.ecs.aws_lb_listener.https"},
+			"ALB-002": {"module.ecs.aws_lb_listener.http_redirect"},
+			"RDS-002": {"module.rds.aws_db_instance.this"},
+			"S3-001":  {`module.s3.aws_s3_bucket.scratch["a"]`},
+			"S3-002":  {"module.s3.aws_s3_bucket.exports"},
+			"S3-003":  {"module.s3.aws_s3_bucket_public_access_block.exports"},
+			"TAG-001": {"module.rds.aws_db_instance.this"},
+		}, found)
+	})
+
+	t.Run("unknown values stay unknown", func(t *testing.T) {
+		ws := loadPlan(t, "compliant.json")
+		ecs := ws.RootModule().Child("ecs")
+		require.NotNil(t, ecs)
+
+		// certificate_arn is only known after apply, so it is written as the
+		// reference its configuration makes.
+		listener := findBlock(t, ws, "module.ecs", "aws_lb_listener.https")
+		attr, ok := listener.Body.Attributes["certificate_arn"]
+		require.True(t, ok, "expected certificate_arn on the planned listener")
+		traversal, diags := hcl.AbsTraversalForExpr(attr.Expr)
+		require.False(t, diags.HasErrors(), diags.Error())
+		require.Equal(t, "var", traversal.RootName())
+		require.Equal(t, "acm_certificate_arn", traversal[1].(hcl.TraverseAttr).Name)
+
+		bucket := findBlock(t, ws, "module.s3", "aws_s3_bucket.photos")
+		arn, ok := bucket.Body.Attributes["arn"]
+		require.True(t, ok, "expected the unknown arn to be kept")
+		val, diags := ws.Evaluator(ws.RootModule().Child("s3")).Value(arn.Expr)
+		require.False(t, diags.HasErrors(), diags.Error())
+		require.False(t, val.IsKnown())
+
+		root := ws.Evaluator(ws.RootModule())
+		require.Equal(t, cty.StringVal("dev"), root.Variable("environment"))
+	})
+
+	t.Run("destructive changes need approval", func(t *testing.T) {
+		guard, err := policy.NewEngine("PLAN-001")
+		require.NoError(t, err)
+
+		ws := loadPlan(t, "destroy.json")
+		var messages []string
+		for _, v := range guard.Run(ws) {
+			messages = append(messages, v.String())
+		}
+		plan := filepath.Join(planFixtures, "destroy.json")
+		require.Equal(t, []string{
+			plan + ": [PLAN-001] module.rds.aws_db_instance.this: plan replaces stateful aws_db_instance, destroying the data it holds (actions delete,create)",
+			plan + ": [PLAN-001] module.s3.aws_s3_bucket.photos: plan deletes stateful aws_s3_bucket, destroying the data it holds (actions delete)",
+			plan + ": [PLAN-001] plan destroys 7 resources, more than the 5 allowed without approval: module.rds.aws_db_instance.this, module.s3.aws_s3_bucket.photos, " +
+				"module.s3.aws_s3_bucket_public_access_block.photos, module.ecs.aws_ecs_service.app, module.ecs.aws_ecs_task_definition.app, " +
+				"module.ecs.aws_lb_target_group.app, module.ecs.aws_security_group.tasks",
+		}, messages)
+
+		// Approved addresses are neither flagged nor counted.
+		ws.DestroyGuard.Allowed = []string{"module.rds.aws_db_instance.this", "module.s3.aws_s3_bucket.photos"}
+		require.Empty(t, guard.Run(ws))
+
+		ws.DestroyGuard.MaxDestroyed = 2
+		violations := guard.Run(ws)
+		require.Len(t, violations, 1)
+		require.Contains(t, violations[0].Message, "plan destroys 5 resources, more than the 2 allowed")
+	})
+
+	t.Run("plan-only rules skip configurations", func(t *testing.T) {
+		rule, ok := policy.Lookup("PLAN-001")
+		require.True(t, ok)
+		require.True(t, policy.PlanOnly(rule))
+		require.NotContains(t, policy.NewReport(loadWorkspace(t, ""), engine, nil).Rules, rule)
+	})
+}
@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "ACM-001",
+		description: "The ACM certificate must cover the environment domain, validate via DNS and be created before destroy",
+		severity:    SeverityError,
+		traces:      []Trace{stagingACM.trace("6.1")},
+		scope:       ScopeEnvironment,
+		evaluate: func(ws *Workspace) []Violation {
+			return evaluateACMResources(ws, "aws_acm_certificate", "", workspaceViolation("expected an aws_acm_certificate resource", "declare aws_acm_certificate.this for var.domain_name"), checkACMCertificate)
+		},
+	})
+
+	Register(&goRule{
+		id:          "ACM-002",
+		description: "ACM validation records must be created in the environment's Route 53 zone",
+		severity:    SeverityError,
+		traces:      []Trace{stagingACM.trace("6.2")},
+		scope:       ScopeEnvironment,
+		evaluate: func(ws *Workspace) []Violation {
+			return evaluateACMResources(ws, "aws_route53_record", "certificate_validation", workspaceViolation("expected Route 53 validation records for the certificate", "declare aws_route53_record.certificate_validation in var.route53_zone_id"), checkCertificateValidationRecord)
+		},
+	})
+
+	Register(&goRule{
+		id:          "ACM-003",
+		description: "aws_acm_certificate_validation must wait on the certificate and its validation records",
+		severity:    SeverityError,
+		traces:      []Trace{stagingALB.trace("6.4")},
+		evaluate: func(ws *Workspace) []Violation {
+			return requireBlocks(ws, ws.Resources("aws_acm_certificate_validation"), workspaceViolation("expected aws_acm_certificate_validation resource", "declare aws_acm_certificate_validation for aws_acm_certificate.this"), checkCertificateValidationResource)
+		},
+	})
+}
+
+type acmExpectations struct {
+	domainName string
+	zoneID     string
+}
+
+func loadACMExpectations(ws *Workspace) (acmExpectations, []Violation) {
+	domain, violations := environmentString(ws, "domain_name")
+	if violations != nil {
+		return acmExpectations{}, violations
+	}
+
+	zoneID, violations := environmentString(ws, "route53_zone_id")
+	if violations != nil {
+		return acmExpectations{}, violations
+	}
+
+	return acmExpectations{domainName: domain, zoneID: zoneID}, nil
+}
+
+func evaluateACMResources(ws *Workspace, resourceType string, name string, missing Violation, check func(*Block, *Evaluator, string, acmExpectations) []Violation) []Violation {
+	expected, violations := loadACMExpectations(ws)
+	if violations != nil {
+		return violations
+	}
+
+	var blocks []*Block
+	for _, block := range ws.Resources(resourceType) {
+		if name == "" || block.Labels[1] == name {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return requireBlocks(ws, blocks, missing, func(block *Block) []Violation {
+		return checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+			return check(block, ev, address, expected)
+		})
+	})
+}
+
+func checkACMCertificate(block *Block, ev *Evaluator, address string, expected acmExpectations) []Violation {
+	var violations []Violation
+
+	const domainRemediation = "set domain_name = var.domain_name"
+
+	domainAttr, ok := block.Body.Attributes["domain_name"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws_acm_certificate missing domain_name", domainRemediation))
+	} else {
+		val, diag := ev.Value(domainAttr.Expr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(domainAttr.Range(), address, fmt.Sprintf("domain_name must be a resolvable string (%s)", diag.Error()), domainRemediation))
+		} else if str, ok := knownString(val); !ok {
+			violations = append(violations, newViolation(domainAttr.Range(), address, "domain_name must be a string known before apply", domainRemediation))
+		} else if str != expected.domainName {
+			violations = append(violations, newViolation(domainAttr.Range(), address, fmt.Sprintf("domain_name set to %s (expected %s)", str, expected.domainName), domainRemediation))
+		}
+	}
+
+	const validationRemediation = "set validation_method = \"DNS\""
+
+	validationAttr, ok := block.Body.Attributes["validation_method"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws_acm_certificate missing validation_method", validationRemediation))
+	} else {
+		val, diag := ev.Value(validationAttr.Expr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(validationAttr.Range(), address, fmt.Sprintf("validation_method must be a resolvable string (%s)", diag.Error()), validationRemediation))
+		} else if str, ok := knownString(val); !ok {
+			violations = append(violations, newViolation(validationAttr.Range(), address, "validation_method must be a string known before apply", validationRemediation))
+		} else if strings.ToUpper(str) != "DNS" {
+			violations = append(violations, newViolation(validationAttr.Range(), address, "validation_method must be DNS", validationRemediation))
+		}
+	}
+
+	const lifecycleRemediation = "add lifecycle { create_before_destroy = true }"
+	lifecycleFix := setAttributeFix(block, []string{"lifecycle"}, "create_before_destroy", cty.True)
+
+	hasLifecycle := false
+	for _, child := range block.Body.Blocks {
+		if child.Type != "lifecycle" {
+			continue
+		}
+		hasLifecycle = true
+		attr, ok := child.Body.Attributes["create_before_destroy"]
+		if !ok {
+			violations = append(violations, withFix(newViolation(child.DefRange(), address, "lifecycle block missing create_before_destroy", lifecycleRemediation), lifecycleFix))
+			continue
+		}
+		val, diag := constValue(attr.Expr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("create_before_destroy must be a constant bool (%s)", diag.Error()), lifecycleRemediation))
+			continue
+		}
+		if val.Type() != cty.Bool || !val.True() {
+			violations = append(violations, withFix(newViolation(attr.Range(), address, "create_before_destroy must be true", lifecycleRemediation), literalFix(attr.Expr, lifecycleFix)))
+		}
+	}
+	if !hasLifecycle {
+		violations = append(violations, withFix(newViolation(block.DefRange(), address, "aws_acm_certificate missing lifecycle create_before_destroy", lifecycleRemediation), lifecycleFix))
+	}
+
+	return violations
+}
+
+func checkCertificateValidationRecord(block *Block, ev *Evaluator, address string, expected acmExpectations) []Violation {
+	const remediation = "set zone_id = var.route53_zone_id"
+
+	var violations []Violation
+
+	zoneAttr, ok := block.Body.Attributes["zone_id"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "certificate validation record missing zone_id", remediation))
+	} else {
+		val, diag := ev.Value(zoneAttr.Expr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(zoneAttr.Range(), address, fmt.Sprintf("zone_id must resolve to route53_zone_id (%s)", diag.Error()), remediation))
+		} else if str, ok := knownString(val); !ok {
+			violations = append(violations, newViolation(zoneAttr.Range(), address, "zone_id must be a string known before apply", remediation))
+		} else if str != expected.zoneID {
+			violations = append(violations, newViolation(zoneAttr.Range(), address, fmt.Sprintf("zone_id set to %s (expected %s)", str, expected.zoneID), remediation))
+		}
+	}
+
+	return violations
+}
+
+func checkCertificateValidationResource(block *Block) []Violation {
+	var violations []Violation
+	address := block.Address
+
+	const arnRemediation = "set certificate_arn = aws_acm_certificate.this.arn"
+
+	arnAttr, ok := block.Body.Attributes["certificate_arn"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws_acm_certificate_validation missing certificate_arn", arnRemediation))
+	} else if !referencesResource(arnAttr.Expr, "aws_acm_certificate", "this") {
+		violations = append(violations, newViolation(arnAttr.Range(), address, "certificate_arn should reference aws_acm_certificate.this", arnRemediation))
+	}
+
+	const recordsRemediation = "set validation_record_fqdns = [for record in aws_route53_record.certificate_validation : record.fqdn]"
+
+	recordsAttr, ok := block.Body.Attributes["validation_record_fqdns"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws_acm_certificate_validation missing validation_record_fqdns", recordsRemediation))
+	} else if !referencesResource(recordsAttr.Expr, "aws_route53_record", "certificate_validation") {
+		violations = append(violations, newViolation(recordsAttr.Range(), address, "validation_record_fqdns should reference aws_route53_record.certificate_validation", recordsRemediation))
+	}
+
+	return violations
+}
@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "ALB-001",
+		description: "The ALB must serve HTTPS on 443 with a TLS 1.2+ policy and an ACM certificate",
+		severity:    SeverityError,
+		traces:      []Trace{stagingALB.trace("4.3", "6.3")},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return evaluateALBListeners(ws, 443, workspaceViolation("expected an HTTPS listener on port 443", "add an aws_lb_listener on port 443 with protocol HTTPS"), func(block *Block) []Violation {
+				return checkHTTPSListener(ws, block)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "ALB-002",
+		description: "The ALB HTTP listener on port 80 must redirect to HTTPS",
+		severity:    SeverityError,
+		traces:      []Trace{stagingALB.trace("4.4")},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return evaluateALBListeners(ws, 80, workspaceViolation("expected an HTTP listener redirecting to HTTPS", "add an aws_lb_listener on port 80 that redirects to HTTPS"), checkHTTPRedirectListener)
+		},
+	})
+}
+
+func evaluateALBListeners(ws *Workspace, port int, missing Violation, check func(*Block) []Violation) []Violation {
+	var violations []Violation
+	found := false
+
+	for _, block := range ws.Resources("aws_lb_listener") {
+		if !ws.inFocus(block) {
+			continue
+		}
+		portAttr, okPort := block.Body.Attributes["port"]
+		if !okPort {
+			violations = append(violations, newViolation(block.DefRange(), block.Address, "aws_lb_listener missing port", "set port on the listener"))
+			continue
+		}
+
+		if isConstNumber(portAttr, port) {
+			found = true
+			violations = append(violations, check(block)...)
+		}
+	}
+
+	if !found {
+		violations = append(violations, missing)
+	}
+
+	return violations
+}
+
+func checkHTTPSListener(ws *Workspace, block *Block) []Violation {
+	var violations []Violation
+	address := block.Address
+
+	protoAttr, ok := block.Body.Attributes["protocol"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "HTTPS listener missing protocol", "set protocol = \"HTTPS\""))
+	} else if !isConstString(protoAttr, "HTTPS") {
+		violations = append(violations, newViolation(protoAttr.Range(), address, "protocol must be HTTPS", "set protocol = \"HTTPS\""))
+	}
+
+	const sslRemediation = "use a TLS 1.2+ policy such as ELBSecurityPolicy-TLS13-1-2-2021-06"
+
+	sslAttr, ok := block.Body.Attributes["ssl_policy"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "HTTPS listener missing ssl_policy", sslRemediation))
+	} else {
+		val, diag := constValue(sslAttr.Expr)
+		if diag.HasErrors() || val.Type() != cty.String {
+			violations = append(violations, newViolation(sslAttr.Range(), address, "ssl_policy must be a constant string", sslRemediation))
+		} else if !isTLS12OrHigher(val.AsString()) {
+			violations = append(violations, newViolation(sslAttr.Range(), address, "ssl_policy must enforce TLS 1.2+", sslRemediation))
+		}
+	}
+
+	const certRemediation = "set certificate_arn = aws_acm_certificate.this.arn or var.acm_certificate_arn"
+
+	certAttr, ok := block.Body.Attributes["certificate_arn"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "HTTPS listener missing certificate_arn", certRemediation))
+	} else {
+		violations = append(violations, checkCertificateARN(ws, block, certAttr, certRemediation)...)
+	}
+
+	return violations
+}
+
+func checkHTTPRedirectListener(block *Block) []Violation {
+	const redirectRemediation = "use default_action { type = \"redirect\" redirect { port = \"443\" protocol = \"HTTPS\" status_code = \"HTTP_301\" } }"
+
+	var violations []Violation
+	address := block.Address
+	redirectFix := blockFix(block, "redirect the default_action to HTTPS", setHTTPSRedirect)
+
+	protoFix := setAttributeFix(block, nil, "protocol", cty.StringVal("HTTP"))
+	protoAttr, ok := block.Body.Attributes["protocol"]
+	if !ok {
+		violations = append(violations, withFix(newViolation(block.DefRange(), address, "HTTP listener missing protocol", "set protocol = \"HTTP\""), protoFix))
+	} else if !isConstString(protoAttr, "HTTP") {
+		violations = append(violations, withFix(newViolation(protoAttr.Range(), address, "protocol must be HTTP", "set protocol = \"HTTP\""), literalFix(protoAttr.Expr, protoFix)))
+	}
+
+	foundRedirect := false
+	for _, child := range block.Body.Blocks {
+		if child.Type != "default_action" {
+			continue
+		}
+
+		actionType, hasType := child.Body.Attributes["type"]
+		if !hasType || !isConstString(actionType, "redirect") {
+			violations = append(violations, withFix(newViolation(child.DefRange(), address, "default_action must be type redirect", redirectRemediation), redirectFix))
+			continue
+		}
+
+		for _, redirect := range child.Body.Blocks {
+			if redirect.Type != "redirect" {
+				continue
+			}
+
+			foundRedirect = true
+
+			if portAttr, ok := redirect.Body.Attributes["port"]; !ok || !isConstString(portAttr, "443") {
+				violations = append(violations, withFix(newViolation(redirect.DefRange(), address, "redirect.port must be \"443\"", "set port = \"443\""), redirectFix))
+			}
+
+			if protoAttr, ok := redirect.Body.Attributes["protocol"]; !ok || !isConstString(protoAttr, "HTTPS") {
+				violations = append(violations, withFix(newViolation(redirect.DefRange(), address, "redirect.protocol must be HTTPS", "set protocol = \"HTTPS\""), redirectFix))
+			}
+
+			if statusAttr, ok := redirect.Body.Attributes["status_code"]; !ok || !isConstString(statusAttr, "HTTP_301") {
+				violations = append(violations, withFix(newViolation(redirect.DefRange(), address, "redirect.status_code must be HTTP_301", "set status_code = \"HTTP_301\""), redirectFix))
+			}
+		}
+	}
+
+	if !foundRedirect {
+		violations = append(violations, withFix(newViolation(block.DefRange(), address, "HTTP listener missing redirect default_action", redirectRemediation), redirectFix))
+	}
+
+	return violations
+}
+
+// setHTTPSRedirect replaces a listener's default actions with a permanent
+// redirect to HTTPS on 443, keeping any other settings of an existing
+// redirect such as its host or path.
+func setHTTPSRedirect(body *hclwrite.Body) {
+	var redirect *hclwrite.Block
+	for _, action := range body.Blocks() {
+		if action.Type() != "default_action" {
+			continue
+		}
+		if redirect == nil {
+			redirect = action.Body().FirstMatchingBlock("redirect", nil)
+		}
+		body.RemoveBlock(action)
+	}
+
+	action := body.AppendNewBlock("default_action", nil)
+	action.Body().SetAttributeValue("type", cty.StringVal("redirect"))
+	if redirect == nil {
+		redirect = hclwrite.NewBlock("redirect", nil)
+	}
+	redirect.Body().SetAttributeValue("port", cty.StringVal("443"))
+	redirect.Body().SetAttributeValue("protocol", cty.StringVal("HTTPS"))
+	redirect.Body().SetAttributeValue("status_code", cty.StringVal("HTTP_301"))
+	action.Body().AppendBlock(redirect)
+}
+
+// isTLS12OrHigher reports whether an ELB security policy name promises a
+// minimum of TLS 1.2. Besides the TLS-1-2 and TLS-1-3 policies, that is the
+// ELBSecurityPolicy-TLS13-* family, whose names give TLS 1.3 support followed
+// by the minimum version, and the forward secrecy FS-1-2 policies.
+func isTLS12OrHigher(policy string) bool {
+	upper := strings.ToUpper(policy)
+	for _, marker := range []string{"TLS-1-2", "TLS-1-3", "TLS13-1-2", "TLS13-1-3", "FS-1-2"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCertificateARN follows certificate_arn through every instance of the
+// listener's module and reports values that do not come from an ACM
+// certificate.
+func checkCertificateARN(ws *Workspace, block *Block, attr *hclsyntax.Attribute, remediation string) []Violation {
+	if val, diag := constValue(attr.Expr); !diag.HasErrors() {
+		if val.Type() != cty.String || val.AsString() == "" {
+			return []Violation{newViolation(attr.Range(), block.Address, "certificate_arn must reference ACM certificate", remediation)}
+		}
+		return nil
+	}
+
+	var violations []Violation
+	for _, inst := range ws.Instances(block.Module) {
+		address := inst.qualify(block.Address)
+		origins := ws.TraceOrigins(inst, attr.Expr)
+
+		var others []string
+		fromACM := false
+		unbound := false
+		for _, origin := range origins {
+			switch {
+			case origin.IsResource("aws_acm_certificate"), origin.IsResource("aws_acm_certificate_validation"):
+				fromACM = true
+			case !origin.IsVariable():
+				others = append(others, origin.String())
+			case origin.Instance.detached():
+				unbound = true
+			}
+		}
+
+		switch {
+		case len(others) > 0:
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("certificate_arn traces to %s instead of an ACM certificate", strings.Join(others, ", ")), remediation))
+		case fromACM:
+		case unbound && !isVarReference(attr.Expr, "acm_certificate_arn"):
+			violations = append(violations, newViolation(attr.Range(), address, "certificate_arn must reference ACM certificate", remediation))
+		}
+	}
+
+	return violations
+}
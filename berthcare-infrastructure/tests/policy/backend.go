@@ -0,0 +1,67 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	stateBucket    = "berthcare-terraform-state"
+	stateLockTable = "berthcare-terraform-locks"
+	backendAddress = "terraform.backend.s3"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "BACKEND-001",
+		description: "The environment state backend must use the shared encrypted, locked bucket with an environment-specific key",
+		severity:    SeverityError,
+		traces:      []Trace{devBackend.trace("6.1", "6.2", "6.3"), stagingBackend.trace("7.1", "7.2", "7.3")},
+		scope:       ScopeEnvironment,
+		evaluate:    evaluateStateBackend,
+	})
+}
+
+func evaluateStateBackend(ws *Workspace) []Violation {
+	backendPath, attrs, err := ws.environmentAttributes("backend.hcl")
+	if err != nil {
+		return []Violation{errorViolation(err)}
+	}
+
+	expectedStrings := []struct {
+		name     string
+		expected string
+		reason   string
+	}{
+		{"bucket", stateBucket, "state bucket must be " + stateBucket},
+		{"key", fmt.Sprintf("envs/%s/terraform.tfstate", ws.Environment), fmt.Sprintf("state key must be unique to %s environment", ws.Environment)},
+		{"region", ExpectedRegion, "backend region must be " + ExpectedRegion},
+		{"dynamodb_table", stateLockTable, "backend must enable DynamoDB state locking"},
+	}
+
+	var violations []Violation
+
+	for _, attr := range expectedStrings {
+		val, attrViolations := requiredStringAttr(attrs, backendPath, attr.name)
+		if attrViolations != nil {
+			violations = append(violations, attrViolations...)
+			continue
+		}
+		if val != attr.expected {
+			violations = append(violations, newViolation(attrs[attr.name].Range(), backendAddress, fmt.Sprintf("%s (got %s)", attr.reason, val), fmt.Sprintf("set %s = %q", attr.name, attr.expected)))
+		}
+	}
+
+	encryptAttr, ok := attrs["encrypt"]
+	if !ok {
+		return append(violations, fileViolation(backendPath, "missing encrypt", "set encrypt = true"))
+	}
+
+	val, diag := constValue(encryptAttr.Expr)
+	if diag.HasErrors() || val.Type() != cty.Bool || !val.True() {
+		violations = append(violations, newViolation(encryptAttr.Range(), backendAddress, "backend must enable encryption", "set encrypt = true"))
+	}
+
+	return violations
+}
@@ -0,0 +1,218 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BaselineFile is the conventional name of the baseline within a Terraform
+// root.
+const BaselineFile = "policy-baseline.json"
+
+// baselineSchemaVersion is bumped whenever the baseline file format or the
+// fingerprint changes incompatibly.
+const baselineSchemaVersion = 1
+
+// Baseline records the violations a configuration is known to have, so that
+// a new rule can land before every existing finding is fixed and a check
+// fails only on violations introduced since.
+type Baseline struct {
+	Entries []BaselineEntry
+}
+
+// BaselineEntry is one accepted violation. Only the fingerprint is matched;
+// the other fields tell reviewers what was accepted.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Rule        string `json:"rule"`
+	Environment string `json:"environment,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	File        string `json:"file,omitempty"`
+	Message     string `json:"message"`
+}
+
+type baselineJSON struct {
+	SchemaVersion int             `json:"schema_version"`
+	Tool          string          `json:"tool"`
+	Violations    []BaselineEntry `json:"violations"`
+}
+
+// Fingerprint identifies a violation across runs. It covers the rule,
+// environment, resource, file relative to root and message, but not the
+// position, so that editing elsewhere in a file does not invalidate it.
+func Fingerprint(root string, v Violation) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{v.RuleID, v.Environment, v.Resource, relativeFile(root, v.Range.Filename), v.Message}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func relativeFile(root string, filename string) string {
+	if filename == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(root, filename); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filename)
+}
+
+func newBaselineEntry(root string, v Violation) BaselineEntry {
+	return BaselineEntry{
+		Fingerprint: Fingerprint(root, v),
+		Rule:        v.RuleID,
+		Environment: v.Environment,
+		Resource:    v.Resource,
+		File:        relativeFile(root, v.Range.Filename),
+		Message:     v.Message,
+	}
+}
+
+// ReadBaseline reads a baseline file written by WriteFile.
+func ReadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file baselineJSON
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.SchemaVersion != baselineSchemaVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline schema version %d", path, file.SchemaVersion)
+	}
+	return &Baseline{Entries: file.Violations}, nil
+}
+
+// WriteFile writes the baseline to path, sorted so that refreshing it gives
+// a reviewable diff.
+func (b *Baseline) WriteFile(path string) error {
+	entries := append([]BaselineEntry{}, b.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, c := entries[i], entries[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		if a.Rule != c.Rule {
+			return a.Rule < c.Rule
+		}
+		if a.Environment != c.Environment {
+			return a.Environment < c.Environment
+		}
+		if a.Resource != c.Resource {
+			return a.Resource < c.Resource
+		}
+		return a.Message < c.Message
+	})
+
+	content, err := json.MarshalIndent(baselineJSON{SchemaVersion: baselineSchemaVersion, Tool: toolName, Violations: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// Update returns the baseline with the entries for every rule and
+// environment the report evaluated replaced by the report's violations,
+// new and baselined alike. Entries for rules or environments the report
+// did not evaluate are kept.
+func (b *Baseline) Update(report *Report) *Baseline {
+	updated := &Baseline{Entries: b.outside(report)}
+	for _, v := range append(report.Violations(), report.Baselined()...) {
+		updated.Entries = append(updated.Entries, newBaselineEntry(report.Root, v))
+	}
+	return updated
+}
+
+// Prune returns the baseline without the entries the report shows are
+// fixed. Unlike Update it never accepts a new violation.
+func (b *Baseline) Prune(report *Report) *Baseline {
+	remaining := b.counts()
+	pruned := &Baseline{Entries: b.outside(report)}
+	for _, v := range append(report.Violations(), report.Baselined()...) {
+		entry := newBaselineEntry(report.Root, v)
+		if remaining[entry.Fingerprint] > 0 {
+			remaining[entry.Fingerprint]--
+			pruned.Entries = append(pruned.Entries, entry)
+		}
+	}
+	return pruned
+}
+
+// counts returns how many times each fingerprint is accepted. The same
+// finding may legitimately be reported more than once.
+func (b *Baseline) counts() map[string]int {
+	counts := map[string]int{}
+	for _, entry := range b.Entries {
+		counts[entry.Fingerprint]++
+	}
+	return counts
+}
+
+// outside returns the entries for rules and environments the report did
+// not evaluate.
+func (b *Baseline) outside(report *Report) []BaselineEntry {
+	evaluated := report.evaluated()
+	var entries []BaselineEntry
+	for _, entry := range b.Entries {
+		if !evaluated[entry.Rule+"\x00"+entry.Environment] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ApplyBaseline moves the violations the baseline accepts out of each
+// result's Violations and into its Baselined, and records in Fixed the
+// entries for evaluated rules that no longer match any violation.
+func (r *Report) ApplyBaseline(b *Baseline) {
+	remaining := b.counts()
+	for i := range r.Results {
+		result := &r.Results[i]
+
+		var kept []Violation
+		for _, v := range result.Violations {
+			fingerprint := Fingerprint(r.Root, v)
+			if remaining[fingerprint] > 0 {
+				remaining[fingerprint]--
+				result.Baselined = append(result.Baselined, v)
+				continue
+			}
+			kept = append(kept, v)
+		}
+		result.Violations = kept
+	}
+
+	evaluated := r.evaluated()
+	r.Baseline = b
+	r.Fixed = nil
+	for _, entry := range b.Entries {
+		if evaluated[entry.Rule+"\x00"+entry.Environment] && remaining[entry.Fingerprint] > 0 {
+			remaining[entry.Fingerprint]--
+			r.Fixed = append(r.Fixed, entry)
+		}
+	}
+}
+
+// Baselined returns every violation the baseline accepted, in result order.
+func (r *Report) Baselined() []Violation {
+	var baselined []Violation
+	for _, result := range r.Results {
+		baselined = append(baselined, result.Baselined...)
+	}
+	return baselined
+}
+
+// evaluated returns the rule and environment pairs the report covers.
+func (r *Report) evaluated() map[string]bool {
+	evaluated := map[string]bool{}
+	for _, result := range r.Results {
+		evaluated[result.Rule.ID()+"\x00"+result.Environment] = true
+	}
+	return evaluated
+}
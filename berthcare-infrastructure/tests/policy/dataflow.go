@@ -0,0 +1,209 @@
+package policy
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Origin is a source an expression's value was traced back to.
+type Origin struct {
+	Instance *ModuleInstance
+	// Address is a resource or data source address such as
+	// aws_subnet.private, or var.<name> when the value is supplied from
+	// outside the configuration: a root variable, or a variable of a module
+	// that no module call reaches.
+	Address string
+	// Block is the resource or data block, nil for variables.
+	Block *Block
+}
+
+// String returns the origin's fully qualified address, such as
+// module.vpc.aws_subnet.private.
+func (o Origin) String() string {
+	return o.Instance.qualify(o.Address)
+}
+
+// IsResource reports whether the origin is a resource of the given type.
+func (o Origin) IsResource(resourceType string) bool {
+	return o.Block != nil && o.Block.Type == "resource" && o.Block.Labels[0] == resourceType
+}
+
+// IsVariable reports whether the value enters the configuration through a
+// variable this workspace cannot see the value of.
+func (o Origin) IsVariable() bool {
+	return o.Block == nil
+}
+
+// TraceOrigins follows every reference in expr, evaluated in inst, through
+// module inputs, module outputs and locals until it reaches resources, data
+// sources or variables supplied from outside the configuration. Constants
+// contribute no origins.
+func (ws *Workspace) TraceOrigins(inst *ModuleInstance, expr hcl.Expression) []Origin {
+	t := &tracer{ws: ws, seen: map[string]bool{}, found: map[string]Origin{}}
+	t.expr(inst, expr)
+
+	origins := make([]Origin, 0, len(t.found))
+	for _, origin := range t.found {
+		origins = append(origins, origin)
+	}
+	sort.Slice(origins, func(i, j int) bool { return origins[i].String() < origins[j].String() })
+	return origins
+}
+
+type tracer struct {
+	ws    *Workspace
+	seen  map[string]bool
+	found map[string]Origin
+}
+
+func (t *tracer) add(origin Origin) {
+	t.found[origin.String()] = origin
+}
+
+func (t *tracer) expr(inst *ModuleInstance, expr hcl.Expression) {
+	for _, trav := range expr.Variables() {
+		t.traversal(inst, trav)
+	}
+}
+
+func (t *tracer) traversal(inst *ModuleInstance, trav hcl.Traversal) {
+	names := traversalNames(trav)
+	if len(names) < 2 {
+		return
+	}
+
+	// Guard against reference cycles, which Terraform rejects but a broken
+	// configuration may still contain.
+	key := inst.qualify(names[0] + "." + names[1])
+	if len(names) > 2 {
+		key += "." + names[2]
+	}
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+
+	switch names[0] {
+	case "var":
+		t.variable(inst, names[1])
+	case "local":
+		t.local(inst, names[1])
+	case "module":
+		if len(names) >= 3 {
+			t.moduleOutput(inst, names[1], names[2])
+		}
+	case "data":
+		if len(names) >= 3 {
+			address := "data." + names[1] + "." + names[2]
+			if block := t.ws.moduleBlock(inst.Dir, address); block != nil {
+				t.add(Origin{Instance: inst, Address: address, Block: block})
+			}
+		}
+	case "each", "count", "path", "self", "terraform":
+	default:
+		address := names[0] + "." + names[1]
+		if block := t.ws.moduleBlock(inst.Dir, address); block != nil {
+			t.add(Origin{Instance: inst, Address: address, Block: block})
+		}
+	}
+}
+
+func (t *tracer) variable(inst *ModuleInstance, name string) {
+	if inst.Call != nil {
+		if arg, ok := inst.Call.Body.Attributes[name]; ok {
+			t.expr(inst.Parent, arg.Expr)
+			return
+		}
+	}
+
+	if inst.Call == nil {
+		// Root variables and the variables of unreached modules are set
+		// from outside the configuration.
+		t.add(Origin{Instance: inst, Address: "var." + name})
+		return
+	}
+
+	if decl := t.ws.moduleBlock(inst.Dir, "var."+name); decl != nil {
+		if def, ok := decl.Body.Attributes["default"]; ok {
+			t.expr(inst, def.Expr)
+		}
+	}
+}
+
+func (t *tracer) local(inst *ModuleInstance, name string) {
+	for _, block := range t.ws.Blocks("locals") {
+		if block.Module != inst.Dir {
+			continue
+		}
+		if attr, ok := block.Body.Attributes[name]; ok {
+			t.expr(inst, attr.Expr)
+			return
+		}
+	}
+}
+
+func (t *tracer) moduleOutput(inst *ModuleInstance, call string, output string) {
+	child := inst.Child(call)
+	if child == nil {
+		return
+	}
+
+	decl := t.ws.moduleBlock(child.Dir, "output."+output)
+	if decl == nil {
+		return
+	}
+
+	if value, ok := decl.Body.Attributes["value"]; ok {
+		t.expr(child, value.Expr)
+	}
+}
+
+// traversalNames returns the leading attribute names of a traversal, stopping
+// at the first index step.
+func traversalNames(trav hcl.Traversal) []string {
+	var names []string
+	for _, step := range trav {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		default:
+			return names
+		}
+	}
+	return names
+}
+
+// tracedResources returns the origins of expr that are resources of the
+// given type, along with every origin found.
+func (ws *Workspace) tracedResources(inst *ModuleInstance, expr hclsyntax.Expression, resourceType string) ([]Origin, []Origin) {
+	origins := ws.TraceOrigins(inst, expr)
+	var matches []Origin
+	for _, origin := range origins {
+		if origin.IsResource(resourceType) {
+			matches = append(matches, origin)
+		}
+	}
+	return matches, origins
+}
+
+// otherOrigins returns the addresses of the origins that are resources or
+// data sources, and whether any origin is a variable of a module that no
+// module call reaches. Origins in root variables are neither: their values
+// come from outside the configuration and cannot be checked here.
+func otherOrigins(origins []Origin) ([]string, bool) {
+	var others []string
+	unbound := false
+	for _, origin := range origins {
+		switch {
+		case !origin.IsVariable():
+			others = append(others, origin.String())
+		case origin.Instance.detached():
+			unbound = true
+		}
+	}
+	return others, unbound
+}
@@ -0,0 +1,422 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// PoliciesDir is the directory of a Terraform root that holds its
+// declarative rules.
+const PoliciesDir = "policies"
+
+// policyFile is the schema of a declarative rule file:
+//
+//	rule "RDS-006" {
+//	  description   = "Staging and production databases must have deletion protection"
+//	  severity      = "error"
+//	  resource_type = "aws_db_instance"
+//	  attribute     = "deletion_protection"
+//	  operator      = "equals"
+//	  value         = true
+//	  environments  = ["staging", "production"]
+//	}
+type policyFile struct {
+	Rules []ruleSpec `hcl:"rule,block"`
+}
+
+type ruleSpec struct {
+	ID           string `hcl:"id,label"`
+	Description  string `hcl:"description"`
+	Severity     string `hcl:"severity"`
+	ResourceType string `hcl:"resource_type"`
+	// Attribute names the attribute to check, prefixed with the types of
+	// the nested blocks it is set in, such as versioning_configuration.status.
+	Attribute    string         `hcl:"attribute"`
+	Operator     string         `hcl:"operator"`
+	Value        hcl.Expression `hcl:"value,optional"`
+	Environments []string       `hcl:"environments,optional"`
+	Remediation  string         `hcl:"remediation,optional"`
+	Traces       []traceSpec    `hcl:"trace,block"`
+	DefRange     hcl.Range      `hcl:",def_range"`
+}
+
+type traceSpec struct {
+	Feature      string   `hcl:"feature"`
+	Property     int      `hcl:"property,optional"`
+	Name         string   `hcl:"name"`
+	Requirements []string `hcl:"requirements,optional"`
+}
+
+// operators lists what each operator needs its value to be, or cty.NilType
+// for the operators that take no value.
+var operators = map[string]cty.Type{
+	"equals":     cty.DynamicPseudoType,
+	"not_equals": cty.DynamicPseudoType,
+	"in":         cty.List(cty.DynamicPseudoType),
+	"not_in":     cty.List(cty.DynamicPseudoType),
+	"matches":    cty.String,
+	"at_least":   cty.Number,
+	"at_most":    cty.Number,
+	"present":    cty.NilType,
+	"absent":     cty.NilType,
+}
+
+// declarativeRule is a rule compiled from a policies file. It is evaluated
+// like any rule written in Go.
+type declarativeRule struct {
+	goRule
+	file         string
+	environments []string
+}
+
+// AppliesTo reports whether the rule checks the named environment. A rule
+// that lists no environments checks them all.
+func (r *declarativeRule) AppliesTo(env string) bool {
+	return len(r.environments) == 0 || slices.Contains(r.environments, env)
+}
+
+// LoadPolicies compiles the rules declared in every .hcl file of dir. A
+// directory that does not exist declares none. A rule may limit itself only
+// to the named environments, those of the configuration the rules are for,
+// so that a misspelt name is an error rather than a rule that never runs.
+func LoadPolicies(dir string, environments []string) ([]Rule, error) {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.hcl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	seen := map[string]string{}
+	for _, path := range paths {
+		compiled, err := loadPolicyFile(path, environments)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range compiled {
+			if other, ok := seen[rule.ID()]; ok {
+				return nil, fmt.Errorf("%s: rule %s is already declared in %s", path, rule.ID(), other)
+			}
+			seen[rule.ID()] = path
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// RegisterPolicies compiles the rules in dir with LoadPolicies, for a
+// configuration with the named environments, and adds them to the registry. A declarative rule replaces one registered before with
+// the same ID, so policies can be loaded again after they change, but it may
+// not replace a rule written in Go.
+func RegisterPolicies(dir string, environments []string) ([]Rule, error) {
+	rules, err := LoadPolicies(dir, environments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if existing, ok := registry[rule.ID()]; ok {
+			if _, ok := existing.(*declarativeRule); !ok {
+				return nil, fmt.Errorf("%s: rule %s is already registered", rule.(*declarativeRule).file, rule.ID())
+			}
+		}
+	}
+	for _, rule := range rules {
+		registry[rule.ID()] = rule
+	}
+	return rules, nil
+}
+
+func loadPolicyFile(path string, environments []string) ([]Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, diags := hclsyntax.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var spec policyFile
+	if diags := gohcl.DecodeBody(config.Body, nil, &spec); diags.HasErrors() {
+		return nil, diags
+	}
+
+	rules := make([]Rule, 0, len(spec.Rules))
+	for _, r := range spec.Rules {
+		rule, err := compileRule(path, r, environments)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: rule %s: %w", path, r.DefRange.Start.Line, r.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func compileRule(path string, spec ruleSpec, environments []string) (*declarativeRule, error) {
+	severity, err := ParseSeverity(spec.Severity)
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range spec.Environments {
+		if !slices.Contains(environments, env) {
+			return nil, fmt.Errorf("environment %q has no directory under environments/", env)
+		}
+	}
+
+	check := &attributeCheck{
+		resourceType: spec.ResourceType,
+		attribute:    spec.Attribute,
+		operator:     spec.Operator,
+		remediation:  spec.Remediation,
+	}
+	if spec.ResourceType == "" || spec.Attribute == "" {
+		return nil, fmt.Errorf("resource_type and attribute must not be empty")
+	}
+	segments := strings.Split(spec.Attribute, ".")
+	check.blocks, check.name = segments[:len(segments)-1], segments[len(segments)-1]
+
+	want, ok := operators[spec.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q (want %s)", spec.Operator, strings.Join(sortedKeys(operators), ", "))
+	}
+	value, diags := spec.Value.Value(nil)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("value must be a constant: %s", diags.Error())
+	}
+	switch {
+	case want == cty.NilType && !value.IsNull():
+		return nil, fmt.Errorf("operator %s takes no value", spec.Operator)
+	case want != cty.NilType && value.IsNull():
+		return nil, fmt.Errorf("operator %s needs a value", spec.Operator)
+	case want != cty.NilType:
+		if check.value, err = convert.Convert(value, want); err != nil {
+			return nil, fmt.Errorf("operator %s needs a value of type %s: %v", spec.Operator, want.FriendlyName(), err)
+		}
+	}
+	if spec.Operator == "matches" {
+		if check.pattern, err = regexp.Compile(check.value.AsString()); err != nil {
+			return nil, err
+		}
+	}
+	if check.remediation == "" {
+		check.remediation = check.defaultRemediation()
+	}
+
+	rule := &declarativeRule{
+		goRule: goRule{
+			id:          spec.ID,
+			description: spec.Description,
+			severity:    severity,
+			evaluate:    check.evaluate,
+		},
+		file:         path,
+		environments: spec.Environments,
+	}
+	// A rule checking some environments needs their values; one checking
+	// the configuration as written also holds for planned and recorded
+	// values.
+	if len(spec.Environments) > 0 {
+		rule.scope = ScopeEnvironment
+	} else {
+		rule.plan = true
+		rule.state = true
+	}
+	for _, t := range spec.Traces {
+		rule.traces = append(rule.traces, Trace{Property: Property{t.Feature, t.Property, t.Name}, Requirements: t.Requirements})
+	}
+	return rule, nil
+}
+
+// attributeCheck compares one attribute of every resource of a type with a
+// value.
+type attributeCheck struct {
+	resourceType string
+	// attribute is the path as written in the policy; blocks are the nested
+	// block types leading to the attribute called name.
+	attribute   string
+	blocks      []string
+	name        string
+	operator    string
+	value       cty.Value
+	pattern     *regexp.Regexp
+	remediation string
+}
+
+func (c *attributeCheck) evaluate(ws *Workspace) []Violation {
+	return checkBlocks(ws, ws.Resources(c.resourceType), func(block *Block) []Violation {
+		fix := c.fix(block)
+		return checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+			bodies := nestedBodies(block.Body, c.blocks)
+			if len(bodies) == 0 {
+				return withFixes(c.checkMissing(block.DefRange(), address), fix)
+			}
+
+			var violations []Violation
+			for _, body := range bodies {
+				attr, ok := body.Attributes[c.name]
+				if !ok {
+					violations = append(violations, withFixes(c.checkMissing(block.DefRange(), address), fix)...)
+					continue
+				}
+				violations = append(violations, withFixes(c.checkAttribute(attr, ev, address), literalFix(attr.Expr, fix))...)
+			}
+			return violations
+		})
+	})
+}
+
+// fix returns the fix for an equals check, which sets the attribute to the
+// value. The other operators leave a choice of values, so they offer none.
+func (c *attributeCheck) fix(block *Block) *Fix {
+	if c.operator != "equals" {
+		return nil
+	}
+	return setAttributeFix(block, c.blocks, c.name, c.value)
+}
+
+// nestedBodies returns the bodies of the blocks reached from body by
+// following the nested block types in path.
+func nestedBodies(body *hclsyntax.Body, path []string) []*hclsyntax.Body {
+	bodies := []*hclsyntax.Body{body}
+	for _, blockType := range path {
+		var next []*hclsyntax.Body
+		for _, b := range bodies {
+			for _, nested := range b.Blocks {
+				if nested.Type == blockType {
+					next = append(next, nested.Body)
+				}
+			}
+		}
+		bodies = next
+	}
+	return bodies
+}
+
+// checkMissing reports an attribute that is not set, which only the
+// negative operators accept.
+func (c *attributeCheck) checkMissing(rng hcl.Range, address string) []Violation {
+	switch c.operator {
+	case "absent", "not_equals", "not_in":
+		return nil
+	}
+	return []Violation{newViolation(rng, address, fmt.Sprintf("%s missing %s", c.resourceType, c.attribute), c.remediation)}
+}
+
+func (c *attributeCheck) checkAttribute(attr *hclsyntax.Attribute, ev *Evaluator, address string) []Violation {
+	fail := func(format string, args ...any) []Violation {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf(format, args...), c.remediation)}
+	}
+
+	switch c.operator {
+	case "present":
+		return nil
+	case "absent":
+		return fail("%s must not be set", c.attribute)
+	}
+
+	val, diags := ev.Value(attr.Expr)
+	if diags.HasErrors() {
+		return fail("%s must be resolvable (%s)", c.attribute, diags.Error())
+	}
+	if !val.IsWhollyKnown() {
+		return fail("%s must be known before apply", c.attribute)
+	}
+	if val.IsNull() {
+		return c.checkMissing(attr.Range(), address)
+	}
+
+	switch c.operator {
+	case "equals":
+		if !c.equal(val, c.value) {
+			return fail("%s is %s (must equal %s)", c.attribute, formatValue(val), formatValue(c.value))
+		}
+	case "not_equals":
+		if c.equal(val, c.value) {
+			return fail("%s must not equal %s", c.attribute, formatValue(c.value))
+		}
+	case "in", "not_in":
+		found := false
+		for it := c.value.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			found = found || c.equal(val, elem)
+		}
+		if found != (c.operator == "in") {
+			verb := "must be one of"
+			if c.operator == "not_in" {
+				verb = "must not be one of"
+			}
+			return fail("%s is %s (%s %s)", c.attribute, formatValue(val), verb, formatValue(c.value))
+		}
+	case "matches":
+		s, err := convert.Convert(val, cty.String)
+		if err != nil || !c.pattern.MatchString(s.AsString()) {
+			return fail("%s is %s (must match %s)", c.attribute, formatValue(val), c.pattern)
+		}
+	case "at_least", "at_most":
+		n, err := convert.Convert(val, cty.Number)
+		if err != nil {
+			return fail("%s is %s (must be a number)", c.attribute, formatValue(val))
+		}
+		cmp := n.AsBigFloat().Cmp(c.value.AsBigFloat())
+		if c.operator == "at_least" && cmp < 0 {
+			return fail("%s is %s (must be at least %s)", c.attribute, formatValue(val), formatValue(c.value))
+		}
+		if c.operator == "at_most" && cmp > 0 {
+			return fail("%s is %s (must be at most %s)", c.attribute, formatValue(val), formatValue(c.value))
+		}
+	}
+	return nil
+}
+
+// equal compares an attribute value with a policy value after converting it
+// to the policy value's type, so that "true" equals true as Terraform would
+// have it.
+func (c *attributeCheck) equal(val cty.Value, want cty.Value) bool {
+	converted, err := convert.Convert(val, want.Type())
+	if err != nil {
+		return false
+	}
+	return converted.Equals(want).True()
+}
+
+func (c *attributeCheck) defaultRemediation() string {
+	switch c.operator {
+	case "equals":
+		return fmt.Sprintf("set %s = %s", c.attribute, formatValue(c.value))
+	case "not_equals", "not_in":
+		return fmt.Sprintf("set %s to anything but %s", c.attribute, formatValue(c.value))
+	case "in":
+		return fmt.Sprintf("set %s to one of %s", c.attribute, formatValue(c.value))
+	case "matches":
+		return fmt.Sprintf("set %s to a value matching %s", c.attribute, c.pattern)
+	case "at_least":
+		return fmt.Sprintf("set %s to %s or more", c.attribute, formatValue(c.value))
+	case "at_most":
+		return fmt.Sprintf("set %s to %s or less", c.attribute, formatValue(c.value))
+	case "absent":
+		return fmt.Sprintf("remove %s", c.attribute)
+	}
+	return fmt.Sprintf("set %s", c.attribute)
+}
+
+// formatValue renders a value as it would be written in HCL.
+func formatValue(val cty.Value) string {
+	return string(hclwrite.TokensForValue(val).Bytes())
+}
@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DestroyGuard configures which destructive changes PLAN-001 lets a plan
+// make.
+type DestroyGuard struct {
+	// StatefulTypes are the resource types whose deletion or replacement
+	// destroys data that cannot be recreated from configuration.
+	StatefulTypes []string
+	// MaxDestroyed is how many resources a plan may destroy in total before
+	// the plan as a whole is flagged.
+	MaxDestroyed int
+	// Allowed holds the resource addresses whose destruction has been
+	// explicitly approved. They are neither flagged nor counted.
+	Allowed []string
+}
+
+// DefaultDestroyGuard is the guard a plan is loaded with: patient records
+// live in the database, and photos and exports in S3 buckets.
+var DefaultDestroyGuard = DestroyGuard{
+	StatefulTypes: []string{
+		"aws_db_instance",
+		"aws_dynamodb_table",
+		"aws_ebs_volume",
+		"aws_efs_file_system",
+		"aws_rds_cluster",
+		"aws_s3_bucket",
+	},
+	MaxDestroyed: 5,
+}
+
+func (g DestroyGuard) clone() DestroyGuard {
+	g.StatefulTypes = slices.Clone(g.StatefulTypes)
+	g.Allowed = slices.Clone(g.Allowed)
+	return g
+}
+
+const destroyRemediation = "keep the existing object (revert the change forcing replacement, or use a moved block for a rename), or approve the destruction with --allow-destroy "
+
+func init() {
+	Register(&goRule{
+		id:          "PLAN-001",
+		description: "Plans must not delete or replace stateful resources, or destroy more than a handful of resources, without explicit approval",
+		severity:    SeverityError,
+		planOnly:    true,
+		evaluate:    evaluateDestructiveChanges,
+	})
+}
+
+func evaluateDestructiveChanges(ws *Workspace) []Violation {
+	guard := ws.DestroyGuard
+
+	var violations []Violation
+	var destroyed []string
+	for _, change := range ws.ResourceChanges() {
+		if !change.Destroys() || slices.Contains(guard.Allowed, change.Address) {
+			continue
+		}
+		destroyed = append(destroyed, change.Address)
+
+		if slices.Contains(guard.StatefulTypes, change.Type) {
+			verb := "deletes"
+			if change.Replaces() {
+				verb = "replaces"
+			}
+			violations = append(violations, Violation{
+				Resource:    change.Address,
+				Message:     fmt.Sprintf("plan %s stateful %s, destroying the data it holds (actions %s)", verb, change.Type, strings.Join(change.Actions, ",")),
+				Remediation: destroyRemediation + change.Address,
+			})
+		}
+	}
+
+	if len(destroyed) > guard.MaxDestroyed {
+		violations = append(violations, workspaceViolation(
+			fmt.Sprintf("plan destroys %d resources, more than the %d allowed without approval: %s", len(destroyed), guard.MaxDestroyed, strings.Join(destroyed, ", ")),
+			"split the change into smaller plans, or approve each destroyed address with --allow-destroy",
+		))
+	}
+	return violations
+}
@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "DNS-001",
+		description: "The environment domain must be a public A alias to the ALB",
+		severity:    SeverityError,
+		traces:      []Trace{stagingDNS.trace("5.1", "5.2")},
+		scope:       ScopeEnvironment,
+		evaluate:    evaluatePublicAliasRecords,
+	})
+}
+
+type dnsExpectations struct {
+	domain string
+	zoneID string
+}
+
+func evaluatePublicAliasRecords(ws *Workspace) []Violation {
+	domain, violations := environmentString(ws, "domain_name")
+	if violations != nil {
+		return violations
+	}
+
+	zoneID, violations := environmentString(ws, "route53_zone_id")
+	if violations != nil {
+		return violations
+	}
+
+	expected := dnsExpectations{domain: domain, zoneID: zoneID}
+
+	found := false
+	for _, block := range ws.Resources("aws_route53_record") {
+		aliases := aliasBlocks(block.Body.Blocks)
+		if len(aliases) == 0 {
+			continue
+		}
+
+		violations = append(violations, checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+			if !recordTargetsDomain(block, ev, expected) {
+				return nil
+			}
+			found = true
+			return checkAliasRecord(block, ev, address, aliases, expected)
+		})...)
+	}
+
+	if !found {
+		violations = append(violations, workspaceViolation(fmt.Sprintf("expected a public ALB alias Route 53 record for the %s domain", ws.Environment), "declare an aws_route53_record of type A aliasing module.ecs.alb_dns_name"))
+	}
+
+	return violations
+}
+
+func aliasBlocks(blocks hclsyntax.Blocks) []*hclsyntax.Block {
+	var aliases []*hclsyntax.Block
+	for _, b := range blocks {
+		if b.Type == "alias" {
+			aliases = append(aliases, b)
+		}
+	}
+	return aliases
+}
+
+func recordTargetsDomain(block *Block, ev *Evaluator, expected dnsExpectations) bool {
+	return attributeEquals(block, ev, "name", expected.domain) || attributeEquals(block, ev, "zone_id", expected.zoneID)
+}
+
+// attributeEquals reports whether the block's attribute evaluates to the
+// expected string.
+func attributeEquals(block *Block, ev *Evaluator, name string, expected string) bool {
+	attr, ok := block.Body.Attributes[name]
+	if !ok {
+		return false
+	}
+	val, diag := ev.Value(attr.Expr)
+	if diag.HasErrors() {
+		return false
+	}
+	str, ok := knownString(val)
+	return ok && str == expected
+}
+
+func checkAliasRecord(block *Block, ev *Evaluator, address string, aliases []*hclsyntax.Block, expected dnsExpectations) []Violation {
+	var violations []Violation
+
+	typeAttr, ok := block.Body.Attributes["type"]
+	if !ok {
+		violations = append(violations, newViolation(block.DefRange(), address, "route53 record missing type", "set type = \"A\""))
+	} else {
+		val, diag := ev.Value(typeAttr.Expr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(typeAttr.Range(), address, fmt.Sprintf("type must be a resolvable string (%s)", diag.Error()), "set type = \"A\""))
+		} else if str, ok := knownString(val); !ok || strings.ToUpper(str) != "A" {
+			violations = append(violations, newViolation(typeAttr.Range(), address, "type must be \"A\"", "set type = \"A\""))
+		}
+	}
+
+	violations = append(violations, checkRecordString(block, ev, address, "name", "domain_name", expected.domain)...)
+	violations = append(violations, checkRecordString(block, ev, address, "zone_id", "route53_zone_id", expected.zoneID)...)
+
+	for _, alias := range aliases {
+		violations = append(violations, checkAliasBlock(address, alias)...)
+	}
+
+	return violations
+}
+
+// checkRecordString requires a record attribute to evaluate to the value the
+// environment gives the named root variable.
+func checkRecordString(block *Block, ev *Evaluator, address string, name string, variable string, expected string) []Violation {
+	remediation := fmt.Sprintf("set %s = var.%s", name, variable)
+
+	attr, ok := block.Body.Attributes[name]
+	if !ok {
+		return []Violation{newViolation(block.DefRange(), address, fmt.Sprintf("route53 record missing %s", name), remediation)}
+	}
+
+	val, diag := ev.Value(attr.Expr)
+	if diag.HasErrors() {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("%s must resolve to %s (%s)", name, variable, diag.Error()), remediation)}
+	}
+
+	str, ok := knownString(val)
+	if !ok {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("%s must resolve to %s before apply", name, variable), remediation)}
+	}
+
+	if str != expected {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("%s set to %s (expected %s)", name, str, expected), remediation)}
+	}
+
+	return nil
+}
+
+func checkAliasBlock(address string, alias *hclsyntax.Block) []Violation {
+	var violations []Violation
+
+	nameAttr, ok := alias.Body.Attributes["name"]
+	if !ok {
+		violations = append(violations, newViolation(alias.DefRange(), address, "alias block missing name", "set name = module.ecs.alb_dns_name"))
+	} else if !referencesModuleOutput(nameAttr.Expr, "ecs", "alb_dns_name") {
+		violations = append(violations, newViolation(nameAttr.Range(), address, "alias name should reference module.ecs.alb_dns_name", "set name = module.ecs.alb_dns_name"))
+	}
+
+	zoneAttr, ok := alias.Body.Attributes["zone_id"]
+	if !ok {
+		violations = append(violations, newViolation(alias.DefRange(), address, "alias block missing zone_id", "set zone_id = module.ecs.alb_zone_id"))
+	} else if !referencesModuleOutput(zoneAttr.Expr, "ecs", "alb_zone_id") {
+		violations = append(violations, newViolation(zoneAttr.Range(), address, "alias zone_id should reference module.ecs.alb_zone_id", "set zone_id = module.ecs.alb_zone_id"))
+	}
+
+	evalAttr, ok := alias.Body.Attributes["evaluate_target_health"]
+	if !ok {
+		violations = append(violations, newViolation(alias.DefRange(), address, "alias block missing evaluate_target_health", "set evaluate_target_health = true"))
+	} else {
+		val, diag := constValue(evalAttr.Expr)
+		if diag.HasErrors() || val.Type() != cty.Bool || !val.True() {
+			violations = append(violations, newViolation(evalAttr.Range(), address, "evaluate_target_health must be true", "set evaluate_target_health = true"))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,101 @@
+package policy
+
+import "github.com/zclconf/go-cty/cty"
+
+func init() {
+	Register(&goRule{
+		id:          "ECS-001",
+		description: "ECS autoscaling groups must launch into private subnets",
+		severity:    SeverityError,
+		traces:      []Trace{devECS.trace("4.5"), stagingECS.trace("4.5"), setupECS.trace()},
+		evaluate: func(ws *Workspace) []Violation {
+			return requireBlocks(ws, ws.Resources("aws_autoscaling_group"), workspaceViolation("expected at least one ECS autoscaling group to validate", "declare an aws_autoscaling_group for the ECS cluster"), func(block *Block) []Violation {
+				return checkSubnetPlacement(ws, block, asgSubnetPlacement)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "ECS-002",
+		description: "The ALB must be placed in public subnets",
+		severity:    SeverityError,
+		traces:      []Trace{stagingALB.trace("4.2")},
+		evaluate: func(ws *Workspace) []Violation {
+			return requireBlocks(ws, ws.Resources("aws_lb"), workspaceViolation("expected at least one ALB to validate", "declare an aws_lb in the public subnets"), func(block *Block) []Violation {
+				return checkSubnetPlacement(ws, block, albSubnetPlacement)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "ECS-003",
+		description: "Private route tables must send 0.0.0.0/0 through a NAT gateway",
+		severity:    SeverityError,
+		traces:      []Trace{devECS.trace("4.5"), stagingECS.trace("4.5")},
+		evaluate:    evaluatePrivateNatRoutes,
+	})
+}
+
+var (
+	asgSubnetPlacement = subnetPlacement{
+		noun:      "autoscaling group",
+		attribute: "vpc_zone_identifier",
+		variable:  "private_subnet_ids",
+	}
+	albSubnetPlacement = subnetPlacement{
+		noun:      "ALB",
+		attribute: "subnets",
+		variable:  "public_subnet_ids",
+		public:    true,
+	}
+)
+
+func evaluatePrivateNatRoutes(ws *Workspace) []Violation {
+	var violations []Violation
+	found := false
+
+	for _, block := range ws.Resources("aws_route_table") {
+		if !ws.inFocus(block) {
+			continue
+		}
+		violations = append(violations, checkNatRoutes(block, &found)...)
+	}
+
+	if !found {
+		violations = append(violations, workspaceViolation("expected at least one NAT gateway route for private subnets", "add a 0.0.0.0/0 route via aws_nat_gateway to the private route table"))
+	}
+
+	return violations
+}
+
+func checkNatRoutes(block *Block, found *bool) []Violation {
+	var violations []Violation
+	address := block.Address
+
+	for _, route := range block.Body.Blocks {
+		if route.Type != "route" {
+			continue
+		}
+
+		natAttr, ok := route.Body.Attributes["nat_gateway_id"]
+		if !ok {
+			continue
+		}
+
+		*found = true
+
+		if cidrAttr, ok := route.Body.Attributes["cidr_block"]; ok {
+			val, cidrDiag := constValue(cidrAttr.Expr)
+			if cidrDiag.HasErrors() || val.Type() != cty.String || val.AsString() != "0.0.0.0/0" {
+				violations = append(violations, newViolation(cidrAttr.Range(), address, "nat route must cover 0.0.0.0/0", "set cidr_block = \"0.0.0.0/0\""))
+			}
+		}
+
+		// Presence of nat_gateway_id is sufficient; allow it to reference a resource.
+		if _, natDiag := constValue(natAttr.Expr); natDiag.HasErrors() && !hasResourceReference(natAttr.Expr, "aws_nat_gateway") {
+			violations = append(violations, newViolation(natAttr.Range(), address, "nat_gateway_id must reference a NAT gateway", "set nat_gateway_id = aws_nat_gateway.<name>.id"))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,274 @@
+package policy
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Engine evaluates a fixed set of rules against workspaces. Rules are
+// independent of each other and of the environment they are evaluated for,
+// so the engine evaluates them concurrently; results come back in the same
+// order however many workers there are.
+type Engine struct {
+	// Workers bounds how many rules are evaluated at once. Zero means one
+	// per CPU.
+	Workers int
+	// RuleTimeout bounds how long one rule may take against one
+	// environment. Zero means no limit. A rule that runs out of time reports
+	// that instead of its findings.
+	RuleTimeout time.Duration
+
+	rules []Rule
+}
+
+// NewEngine selects rules from the registry by ID. With no IDs every
+// registered rule is selected. The syntax and suppression rules are always
+// selected so that files which fail to parse and suppressions which cannot
+// be honored are reported.
+func NewEngine(ids ...string) (*Engine, error) {
+	if len(ids) == 0 {
+		return &Engine{rules: Rules()}, nil
+	}
+
+	for _, id := range []string{suppressionRuleID, syntaxRuleID} {
+		if !slices.Contains(ids, id) {
+			ids = append([]string{id}, ids...)
+		}
+	}
+
+	rules := make([]Rule, 0, len(ids))
+	for _, id := range ids {
+		rule, ok := Lookup(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown rule %s", id)
+		}
+		rules = append(rules, rule)
+	}
+
+	return &Engine{rules: rules}, nil
+}
+
+// Rules returns the rules this engine evaluates.
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Run evaluates every selected rule and returns the combined violations that
+// no active suppression comment accepts.
+func (e *Engine) Run(ws *Workspace) []Violation {
+	var jobs []job
+	for _, rule := range e.rulesFor(ws) {
+		jobs = append(jobs, job{rule, ws})
+	}
+
+	var violations []Violation
+	results, _ := e.runJobs(context.Background(), jobs)
+	for _, result := range results {
+		violations = append(violations, result.Violations...)
+	}
+	return violations
+}
+
+// Result is what one rule reported for one environment. Environment is
+// empty for workspace-scoped rules, which are evaluated once.
+type Result struct {
+	Rule        Rule
+	Environment string
+	Violations  []Violation
+	// Suppressed holds the violations an active suppression comment
+	// accepted. They do not fail the run.
+	Suppressed []Violation
+	// Baselined holds the violations moved aside by Report.ApplyBaseline.
+	Baselined []Violation
+}
+
+func evaluate(rule Rule, ws *Workspace) Result {
+	violations := rule.Evaluate(ws)
+	if ws.renderedFile() != "" {
+		ws.locateRenderedFindings(violations)
+	}
+	sortViolations(violations)
+	kept, suppressed := ws.suppress(violations)
+	return Result{Rule: rule, Environment: ws.Environment, Violations: kept, Suppressed: suppressed}
+}
+
+// sortViolations orders the violations of one rule by file and position, so
+// that reports do not depend on the order a rule walked the files in.
+// Violations at the same place keep the order the rule reported them in.
+func sortViolations(violations []Violation) {
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return cmp.Or(
+			cmp.Compare(a.Range.Filename, b.Range.Filename),
+			cmp.Compare(a.Range.Start.Byte, b.Range.Start.Byte),
+		)
+	})
+}
+
+// job is one rule to evaluate against one view of a workspace.
+type job struct {
+	rule Rule
+	ws   *Workspace
+}
+
+// runJobs evaluates jobs on the engine's workers and returns their results
+// in the order of jobs. When ctx is done it stops handing out jobs, waits
+// for those already running and returns ctx's error.
+func (e *Engine) runJobs(ctx context.Context, jobs []job) ([]Result, error) {
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(jobs))
+
+	results := make([]Result, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = e.evaluateWithin(ctx, jobs[i].rule, jobs[i].ws)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// evaluateWithin is evaluate bounded by ctx and the engine's rule timeout.
+// A running rule cannot be stopped, so one that runs out of time is left to
+// finish in the background and what it finds is dropped.
+func (e *Engine) evaluateWithin(ctx context.Context, rule Rule, ws *Workspace) Result {
+	if e.RuleTimeout <= 0 && ctx.Done() == nil {
+		return evaluate(rule, ws)
+	}
+
+	timeout := ctx
+	if e.RuleTimeout > 0 {
+		var cancel context.CancelFunc
+		timeout, cancel = context.WithTimeout(ctx, e.RuleTimeout)
+		defer cancel()
+	}
+
+	// A rule is not started once there is no time left for it.
+	if timeout.Err() == nil {
+		done := make(chan Result, 1)
+		go func() { done <- evaluate(rule, ws) }()
+		select {
+		case result := <-done:
+			return result
+		case <-timeout.Done():
+		}
+	}
+
+	result := Result{Rule: rule, Environment: ws.Environment}
+	if ctx.Err() == nil {
+		v := workspaceViolation(fmt.Sprintf("%s did not finish within %s", rule.ID(), e.RuleTimeout), "raise --rule-timeout, or make the rule cheaper")
+		v.RuleID = rule.ID()
+		v.Severity = SeverityError
+		v.Environment = ws.Environment
+		result.Violations = []Violation{v}
+	}
+	return result
+}
+
+// rulesFor returns the selected rules that can be evaluated against ws:
+// those that are not plan-only for a configuration, those that support plans
+// for a plan, and those that support state for a state file. Rules that do
+// not apply to the workspace's environment are left out.
+func (e *Engine) rulesFor(ws *Workspace) []Rule {
+	var rules []Rule
+	for _, rule := range e.rules {
+		var ok bool
+		switch {
+		case ws.PlanFile() != "":
+			ok = SupportsPlan(rule)
+		case ws.StateFile() != "":
+			ok = SupportsState(rule)
+		default:
+			ok = !PlanOnly(rule)
+		}
+		if ws.Environment != "" && rule.Scope() == ScopeEnvironment {
+			ok = ok && AppliesTo(rule, ws.Environment)
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// RunMatrix evaluates workspace-scoped rules once against ws and
+// environment-scoped rules against every environment under environments/,
+// each reading that environment's own files. Results are ordered by
+// environment, workspace first, then by rule.
+func (e *Engine) RunMatrix(ws *Workspace) ([]Result, error) {
+	return e.RunMatrixContext(context.Background(), ws)
+}
+
+// RunMatrixContext is RunMatrix, stopping when ctx is done.
+func (e *Engine) RunMatrixContext(ctx context.Context, ws *Workspace) ([]Result, error) {
+	environments, err := ws.Environments()
+	if err != nil {
+		return nil, err
+	}
+	return e.RunEnvironmentsContext(ctx, ws, environments)
+}
+
+// RunEnvironments is RunMatrix for the named environments only.
+func (e *Engine) RunEnvironments(ws *Workspace, environments []string) []Result {
+	results, _ := e.RunEnvironmentsContext(context.Background(), ws, environments)
+	return results
+}
+
+// RunEnvironmentsContext is RunEnvironments, stopping when ctx is done. It
+// returns ctx's error, and no results, when ctx is done before every rule has
+// been evaluated.
+func (e *Engine) RunEnvironmentsContext(ctx context.Context, ws *Workspace, environments []string) ([]Result, error) {
+	return e.runJobs(ctx, e.environmentJobs(ws, environments))
+}
+
+// environmentJobs returns the jobs RunEnvironmentsContext runs: one for each
+// workspace-scoped rule, then one for each environment-scoped rule in each
+// environment it applies to.
+func (e *Engine) environmentJobs(ws *Workspace, environments []string) []job {
+	rules := e.rulesFor(ws)
+
+	var jobs []job
+	for _, rule := range rules {
+		if rule.Scope() == ScopeWorkspace {
+			jobs = append(jobs, job{rule, ws.WithEnvironment("")})
+		}
+	}
+
+	for _, env := range environments {
+		view := ws.WithEnvironment(env)
+		for _, rule := range rules {
+			if rule.Scope() == ScopeEnvironment && AppliesTo(rule, env) {
+				jobs = append(jobs, job{rule, view})
+			}
+		}
+	}
+	return jobs
+}
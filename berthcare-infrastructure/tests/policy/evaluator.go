@@ -0,0 +1,321 @@
+package policy
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Evaluator evaluates expressions inside one module instance with the values
+// the selected environment gives its variables, locals and module outputs.
+// Anything only known after apply, such as resource attributes, evaluates to
+// an unknown value.
+type Evaluator struct {
+	Instance *ModuleInstance
+	ctx      *hcl.EvalContext
+}
+
+// Evaluator returns the evaluator for a module instance. Root variables take
+// their values from the environment's terraform.tfvars, falling back to their
+// defaults; a root variable with neither is unknown. Module variables take
+// the arguments of their module call. The whole module tree is evaluated once
+// per environment and shared by every view of the workspace.
+func (ws *Workspace) Evaluator(inst *ModuleInstance) *Evaluator {
+	ws.index.evalMu.Lock()
+	defer ws.index.evalMu.Unlock()
+
+	ev, ok := ws.index.evaluations[ws.Environment]
+	if !ok {
+		ev = newEvaluation(ws)
+		ev.build(ws.index.root, nil)
+		ws.index.evaluations[ws.Environment] = ev
+	}
+
+	ctx, ok := ev.contexts[inst]
+	if !ok {
+		ctx = ev.build(inst, nil)
+	}
+
+	return &Evaluator{Instance: inst, ctx: ctx}
+}
+
+// Value evaluates expr in the evaluator's module instance.
+func (e *Evaluator) Value(expr hcl.Expression) (cty.Value, hcl.Diagnostics) {
+	return expr.Value(e.ctx)
+}
+
+// Variable returns the value of one of the instance's input variables, or
+// an unknown value when the module declares no such variable.
+func (e *Evaluator) Variable(name string) cty.Value {
+	vars := e.ctx.Variables["var"]
+	if !vars.Type().IsObjectType() || !vars.Type().HasAttribute(name) {
+		return cty.DynamicVal
+	}
+	return vars.GetAttr(name)
+}
+
+// checkInstances runs check once for every instance of the block's module
+// in the workspace's focus, passing an evaluator for the instance and the
+// block's qualified address.
+func checkInstances(ws *Workspace, block *Block, check func(ev *Evaluator, address string) []Violation) []Violation {
+	var violations []Violation
+	for _, inst := range ws.Instances(block.Module) {
+		address := inst.qualify(block.Address)
+		if ws.focus != nil && !ws.focus[address] {
+			continue
+		}
+		violations = append(violations, check(ws.Evaluator(inst), address)...)
+	}
+	return violations
+}
+
+// evaluation holds the evaluation contexts of every module instance for one
+// environment.
+type evaluation struct {
+	ws        *Workspace
+	tfvars    hclsyntax.Attributes
+	functions map[string]function.Function
+	contexts  map[*ModuleInstance]*hcl.EvalContext
+}
+
+func newEvaluation(ws *Workspace) *evaluation {
+	ev := &evaluation{
+		ws:        ws,
+		functions: terraformFunctions(),
+		contexts:  map[*ModuleInstance]*hcl.EvalContext{},
+	}
+
+	// Rules that need the environment's values report a missing or broken
+	// terraform.tfvars themselves; here variables just keep their defaults.
+	if ws.Environment != "" {
+		if _, attrs, err := ws.environmentAttributes("terraform.tfvars"); err == nil {
+			ev.tfvars = attrs
+		}
+	}
+
+	return ev
+}
+
+// build evaluates inst given the context its module call is evaluated in,
+// which is nil for the root module and detached instances.
+func (ev *evaluation) build(inst *ModuleInstance, parent *hcl.EvalContext) *hcl.EvalContext {
+	ctx := &hcl.EvalContext{
+		Variables: ev.resources(inst.Dir),
+		Functions: ev.functions,
+	}
+	ctx.Variables["var"] = ev.variables(inst, parent)
+	ctx.Variables["path"] = cty.ObjectVal(map[string]cty.Value{
+		"module": cty.StringVal(inst.Dir),
+		"root":   cty.StringVal("."),
+		"cwd":    cty.StringVal("."),
+	})
+	ctx.Variables["terraform"] = cty.ObjectVal(map[string]cty.Value{
+		"workspace": cty.StringVal("default"),
+	})
+	for _, name := range []string{"count", "each", "self"} {
+		ctx.Variables[name] = cty.DynamicVal
+	}
+
+	modules := map[string]cty.Value{}
+	for _, child := range inst.Children {
+		modules[child.Name] = cty.DynamicVal
+	}
+
+	// One module's outputs may feed another's inputs, directly or through
+	// locals, so repeat until the outputs settle. Each pass resolves at
+	// least one more module, which bounds the passes by the module count.
+	for pass := 0; pass <= len(inst.Children); pass++ {
+		ctx.Variables["module"] = cty.ObjectVal(modules)
+		ctx.Variables["local"] = ev.locals(inst.Dir, ctx)
+
+		changed := false
+		for _, child := range inst.Children {
+			outputs := ev.outputs(child, ev.build(child, ctx))
+			if !outputs.RawEquals(modules[child.Name]) {
+				modules[child.Name] = outputs
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	ctx.Variables["module"] = cty.ObjectVal(modules)
+	ctx.Variables["local"] = ev.locals(inst.Dir, ctx)
+	ev.contexts[inst] = ctx
+
+	return ctx
+}
+
+// resources returns unknown placeholders for the resources and data sources
+// declared in dir, so that references to them evaluate without errors.
+func (ev *evaluation) resources(dir string) map[string]cty.Value {
+	resources := map[string]map[string]cty.Value{}
+	for _, block := range ev.ws.Blocks("resource") {
+		if block.Module != dir || len(block.Labels) != 2 {
+			continue
+		}
+		if resources[block.Labels[0]] == nil {
+			resources[block.Labels[0]] = map[string]cty.Value{}
+		}
+		resources[block.Labels[0]][block.Labels[1]] = cty.DynamicVal
+	}
+
+	data := map[string]map[string]cty.Value{}
+	for _, block := range ev.ws.Blocks("data") {
+		if block.Module != dir || len(block.Labels) != 2 {
+			continue
+		}
+		if data[block.Labels[0]] == nil {
+			data[block.Labels[0]] = map[string]cty.Value{}
+		}
+		data[block.Labels[0]][block.Labels[1]] = cty.DynamicVal
+	}
+
+	vars := map[string]cty.Value{}
+	for resourceType, names := range resources {
+		vars[resourceType] = cty.ObjectVal(names)
+	}
+
+	dataTypes := map[string]cty.Value{}
+	for dataType, names := range data {
+		dataTypes[dataType] = cty.ObjectVal(names)
+	}
+	vars["data"] = cty.ObjectVal(dataTypes)
+
+	return vars
+}
+
+func (ev *evaluation) variables(inst *ModuleInstance, parent *hcl.EvalContext) cty.Value {
+	vars := map[string]cty.Value{}
+
+	for _, decl := range ev.ws.Blocks("variable") {
+		if decl.Module != inst.Dir || len(decl.Labels) != 1 {
+			continue
+		}
+		name := decl.Labels[0]
+
+		val := cty.DynamicVal
+		if def, ok := decl.Body.Attributes["default"]; ok {
+			val = evaluateOrUnknown(def.Expr, nil)
+		}
+
+		switch {
+		case inst.Call != nil:
+			if arg, ok := inst.Call.Body.Attributes[name]; ok {
+				val = evaluateOrUnknown(arg.Expr, parent)
+			}
+		case inst.Dir == ".":
+			if attr, ok := ev.tfvars[name]; ok {
+				val = evaluateOrUnknown(attr.Expr, nil)
+			}
+		}
+
+		vars[name] = convertVariable(decl, val)
+	}
+
+	return cty.ObjectVal(vars)
+}
+
+// convertVariable converts val to the variable's declared type, as Terraform
+// does before the value is used.
+func convertVariable(decl *Block, val cty.Value) cty.Value {
+	typeAttr, ok := decl.Body.Attributes["type"]
+	if !ok {
+		return val
+	}
+
+	ty, diags := typeexpr.TypeConstraint(typeAttr.Expr)
+	if diags.HasErrors() {
+		return val
+	}
+
+	converted, err := convert.Convert(val, ty)
+	if err != nil {
+		return cty.UnknownVal(ty)
+	}
+	return converted
+}
+
+// locals evaluates the locals declared in dir. Locals may refer to each
+// other in any order, so every local is evaluated once per local declared,
+// which is enough for each chain of references to resolve.
+func (ev *evaluation) locals(dir string, ctx *hcl.EvalContext) cty.Value {
+	attrs := map[string]*hclsyntax.Attribute{}
+	for _, block := range ev.ws.Blocks("locals") {
+		if block.Module != dir {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			attrs[name] = attr
+		}
+	}
+
+	names := make([]string, 0, len(attrs))
+	locals := map[string]cty.Value{}
+	for name := range attrs {
+		names = append(names, name)
+		locals[name] = cty.DynamicVal
+	}
+	sort.Strings(names)
+
+	for range names {
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+
+		changed := false
+		for _, name := range names {
+			val := evaluateOrUnknown(attrs[name].Expr, ctx)
+			if !val.RawEquals(locals[name]) {
+				locals[name] = val
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return cty.ObjectVal(locals)
+}
+
+// outputs evaluates a module instance's outputs in its context. Modules
+// expanded with count or for_each are left unknown.
+func (ev *evaluation) outputs(inst *ModuleInstance, ctx *hcl.EvalContext) cty.Value {
+	if inst.Call != nil {
+		if _, ok := inst.Call.Body.Attributes["count"]; ok {
+			return cty.DynamicVal
+		}
+		if _, ok := inst.Call.Body.Attributes["for_each"]; ok {
+			return cty.DynamicVal
+		}
+	}
+
+	outputs := map[string]cty.Value{}
+	for _, decl := range ev.ws.Blocks("output") {
+		if decl.Module != inst.Dir || len(decl.Labels) != 1 {
+			continue
+		}
+
+		val := cty.DynamicVal
+		if attr, ok := decl.Body.Attributes["value"]; ok {
+			val = evaluateOrUnknown(attr.Expr, ctx)
+		}
+		outputs[decl.Labels[0]] = val
+	}
+
+	return cty.ObjectVal(outputs)
+}
+
+func evaluateOrUnknown(expr hcl.Expression, ctx *hcl.EvalContext) cty.Value {
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal
+	}
+	return val
+}
@@ -0,0 +1,211 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// constValue evaluates expr as a constant, without variables or functions.
+// A null result is an error like any other non-constant, so a value without
+// errors can be read as its type allows; elements of collections may still
+// be null.
+func constValue(expr hclsyntax.Expression) (cty.Value, hcl.Diagnostics) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	if val.IsNull() {
+		return cty.DynamicVal, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Null value",
+			Detail:   "The value must not be null.",
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+	return val, diags
+}
+
+func isConstNumber(attr *hclsyntax.Attribute, expected int) bool {
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return false
+	}
+	if !val.Type().Equals(cty.Number) {
+		return false
+	}
+	if i, _ := val.AsBigFloat().Int64(); i != int64(expected) {
+		return false
+	}
+	return true
+}
+
+func isConstString(attr *hclsyntax.Attribute, expected string) bool {
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return false
+	}
+	if val.Type() != cty.String {
+		return false
+	}
+	return val.AsString() == expected
+}
+
+func isVarReference(expr hclsyntax.Expression, name string) bool {
+	for _, trav := range expr.Variables() {
+		if len(trav) != 2 {
+			continue
+		}
+		root, ok := trav[0].(hcl.TraverseRoot)
+		if !ok || root.Name != "var" {
+			continue
+		}
+		attr, ok := trav[1].(hcl.TraverseAttr)
+		if !ok || attr.Name != name {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func hasResourceReference(expr hclsyntax.Expression, resourceType string) bool {
+	for _, trav := range expr.Variables() {
+		if len(trav) < 2 {
+			continue
+		}
+
+		root, ok := trav[0].(hcl.TraverseRoot)
+		if !ok {
+			continue
+		}
+
+		if root.Name == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+func referencesResource(expr hclsyntax.Expression, resourceType string, name string) bool {
+	for _, trav := range expr.Variables() {
+		if len(trav) < 2 {
+			continue
+		}
+
+		root, ok := trav[0].(hcl.TraverseRoot)
+		if !ok || root.Name != resourceType {
+			continue
+		}
+
+		attr, ok := trav[1].(hcl.TraverseAttr)
+		if !ok || attr.Name != name {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func referencesModuleOutput(expr hclsyntax.Expression, moduleName string, output string) bool {
+	for _, trav := range expr.Variables() {
+		if len(trav) < 3 {
+			continue
+		}
+
+		root, ok := trav[0].(hcl.TraverseRoot)
+		if !ok || root.Name != "module" {
+			continue
+		}
+
+		mod, ok := trav[1].(hcl.TraverseAttr)
+		if !ok || mod.Name != moduleName {
+			continue
+		}
+
+		attr, ok := trav[2].(hcl.TraverseAttr)
+		if !ok || attr.Name != output {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func parseBody(filePath string, content []byte) (*hclsyntax.Body, []Violation) {
+	parsedFile, diag := hclsyntax.ParseConfig(content, filePath, hcl.Pos{Line: 1, Column: 1})
+	if diag.HasErrors() {
+		return nil, diagnosticViolations(filePath, diag)
+	}
+
+	body, ok := parsedFile.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, []Violation{fileViolation(filePath, "expected hclsyntax.Body", "")}
+	}
+
+	return body, nil
+}
+
+// requiredStringAttr reads a constant string from a flat attribute file such
+// as terraform.tfvars or backend.hcl.
+func requiredStringAttr(attrs hclsyntax.Attributes, source string, name string) (string, []Violation) {
+	attr, ok := attrs[name]
+	if !ok {
+		return "", []Violation{fileViolation(source, fmt.Sprintf("missing %s", name), fmt.Sprintf("set %s in %s", name, source))}
+	}
+
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return "", []Violation{newViolation(attr.Range(), "", fmt.Sprintf("%s must be a constant string (%s)", name, diag.Error()), "use a string literal")}
+	}
+	if val.Type() != cty.String {
+		return "", []Violation{newViolation(attr.Range(), "", fmt.Sprintf("%s must be a string literal", name), "use a string literal")}
+	}
+
+	return val.AsString(), nil
+}
+
+// environmentString returns the value the selected environment gives a root
+// string variable. The variable must be set in the environment's
+// terraform.tfvars rather than left to its default, so that every
+// environment states it explicitly.
+func environmentString(ws *Workspace, name string) (string, []Violation) {
+	tfvarsPath, attrs, err := ws.environmentAttributes("terraform.tfvars")
+	if err != nil {
+		return "", []Violation{errorViolation(err)}
+	}
+
+	attr, ok := attrs[name]
+	if !ok {
+		return "", []Violation{fileViolation(tfvarsPath, fmt.Sprintf("missing %s", name), fmt.Sprintf("set %s in %s", name, tfvarsPath))}
+	}
+
+	val, ok := knownString(ws.Evaluator(ws.RootModule()).Variable(name))
+	if !ok {
+		return "", []Violation{newViolation(attr.Range(), "", fmt.Sprintf("%s must be a string", name), "use a string literal")}
+	}
+
+	return val, nil
+}
+
+// knownString returns val as a Go string when it is a known, non-null string.
+func knownString(val cty.Value) (string, bool) {
+	if !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// knownBool returns val as a Go bool when it is a known, non-null bool.
+func knownBool(val cty.Value) (bool, bool) {
+	if !val.IsKnown() || val.IsNull() || val.Type() != cty.Bool {
+		return false, false
+	}
+	return val.True(), true
+}
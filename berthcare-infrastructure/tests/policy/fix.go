@@ -0,0 +1,177 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Fix is an edit to one top-level block of a Terraform file that resolves a
+// violation. Rules offer one only when there is a single obvious way to
+// resolve it, such as setting a missing flag to the value the rule expects.
+type Fix struct {
+	// Description says what the fix changes, such as
+	// "set block_public_acls = true".
+	Description string
+	Filename    string
+	blockType   string
+	labels      []string
+	edit        func(body *hclwrite.Body)
+}
+
+func (f *Fix) key() string {
+	return strings.Join(append([]string{f.Filename, f.blockType, f.Description}, f.labels...), "\x00")
+}
+
+// blockFix returns a fix that edits the body of block.
+func blockFix(block *Block, description string, edit func(body *hclwrite.Body)) *Fix {
+	return &Fix{
+		Description: description,
+		Filename:    block.File.Path,
+		blockType:   block.Type,
+		labels:      block.Labels,
+		edit:        edit,
+	}
+}
+
+// setAttributeFix returns a fix that sets an attribute of block, or of the
+// nested block reached by following the block types in path, creating any
+// nested block that does not exist.
+func setAttributeFix(block *Block, path []string, name string, val cty.Value) *Fix {
+	description := fmt.Sprintf("set %s = %s", strings.Join(append(slices.Clone(path), name), "."), formatValue(val))
+	return blockFix(block, description, func(body *hclwrite.Body) {
+		for _, blockType := range path {
+			nested := body.FirstMatchingBlock(blockType, nil)
+			if nested == nil {
+				nested = body.AppendNewBlock(blockType, nil)
+			}
+			body = nested.Body()
+		}
+		body.SetAttributeValue(name, val)
+	})
+}
+
+// withFix attaches fix to v.
+func withFix(v Violation, fix *Fix) Violation {
+	v.Fix = fix
+	return v
+}
+
+// withFixes attaches fix to each of violations.
+func withFixes(violations []Violation, fix *Fix) []Violation {
+	for i := range violations {
+		violations[i].Fix = fix
+	}
+	return violations
+}
+
+// literalFix returns fix when expr refers to nothing, so that replacing it
+// with the expected constant loses no intent, and nil otherwise.
+func literalFix(expr hclsyntax.Expression, fix *Fix) *Fix {
+	if len(expr.Variables()) > 0 {
+		return nil
+	}
+	return fix
+}
+
+// FileEdit is the result of applying fixes to one file, or of formatting
+// it.
+type FileEdit struct {
+	Path   string
+	Before []byte
+	After  []byte
+	Fixes  []*Fix
+}
+
+// UnifiedDiff returns the edit as a unified diff with three lines of
+// context.
+func (e FileEdit) UnifiedDiff() (string, error) {
+	name := strings.TrimPrefix(filepath.ToSlash(e.Path), "/")
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(e.Before)),
+		B:        difflib.SplitLines(string(e.After)),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+// ApplyFixes applies fixes to the files they edit and returns the edited
+// contents, one FileEdit per file sorted by path, without writing them. Each
+// file is read once, and the blocks the fixes edit are formatted as terraform
+// fmt would; the rest of the file is left exactly as it was. A fix offered
+// for every instance of a module or every environment is applied once.
+func ApplyFixes(fixes []*Fix) ([]FileEdit, error) {
+	byFile := map[string][]*Fix{}
+	seen := map[string]bool{}
+	for _, fix := range fixes {
+		if seen[fix.key()] {
+			continue
+		}
+		seen[fix.key()] = true
+		byFile[fix.Filename] = append(byFile[fix.Filename], fix)
+	}
+
+	var edits []FileEdit
+	for _, path := range sortedKeys(byFile) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := editFile(path, content, byFile[path])
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(after, content) {
+			edits = append(edits, FileEdit{Path: path, Before: content, After: after, Fixes: byFile[path]})
+		}
+	}
+	return edits, nil
+}
+
+// editFile applies fixes to content, the contents of path, and returns the
+// result with the edited blocks formatted.
+func editFile(path string, content []byte, fixes []*Fix) ([]byte, error) {
+	file, diags := hclwrite.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	edited := map[*hclwrite.Token]hclwrite.Tokens{}
+	for _, fix := range fixes {
+		block := file.Body().FirstMatchingBlock(fix.blockType, fix.labels)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no %s block to fix", path, strings.Join(append([]string{fix.blockType}, fix.labels...), "."))
+		}
+		fix.edit(block.Body())
+		// Keyed by the block's first token, which identifies it in the
+		// file's tokens below.
+		tokens := block.BuildTokens(nil)
+		edited[tokens[0]] = tokens
+	}
+
+	// A block is formatted on its own exactly as it is within its file, so
+	// only the edited blocks are passed through the formatter.
+	var out bytes.Buffer
+	tokens := file.BuildTokens(nil)
+	for i := 0; i < len(tokens); i++ {
+		block, ok := edited[tokens[i]]
+		if !ok {
+			tokens[i : i+1].WriteTo(&out)
+			continue
+		}
+		out.Write(hclwrite.Format(block.Bytes()))
+		i += len(block) - 1
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// FormatFiles formats every .tf, .tfvars and .hcl file under root the way
+// terraform fmt lays out HCL, without writing them, and returns a FileEdit
+// for each file whose layout would change, sorted by path. Directories the
+// workspace does not load, such as testdata, are skipped. Files that fail to
+// parse are not formatted; the error names each of them.
+func FormatFiles(root string) ([]FileEdit, error) {
+	paths, err := collectFiles(root, ".tf", ".tfvars", ".hcl")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		edits []FileEdit
+		errs  []error
+	)
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, diags := hclwrite.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+			errs = append(errs, diags)
+			continue
+		}
+
+		if after := hclwrite.Format(content); !bytes.Equal(after, content) {
+			edits = append(edits, FileEdit{Path: path, Before: content, After: after})
+		}
+	}
+	return edits, errors.Join(errs...)
+}
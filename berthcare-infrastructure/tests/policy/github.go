@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterReporter(&reporterFunc{name: "github", write: writeGitHubAnnotations})
+}
+
+// writeGitHubAnnotations writes one GitHub Actions workflow command per
+// violation, such as ::error file=main.tf,line=3,col=5::message, so that the
+// findings annotate the pull request diff. File paths are relative to the
+// working directory, which must be the repository root for GitHub to match
+// them.
+func writeGitHubAnnotations(w io.Writer, report *Report) error {
+	for _, v := range report.Violations() {
+		var props []string
+		if v.Range.Filename != "" {
+			props = append(props, "file="+escapeAnnotationProperty(filepath.ToSlash(v.Range.Filename)))
+		}
+		if v.Range.Start.Line > 0 {
+			props = append(props,
+				fmt.Sprintf("line=%d", v.Range.Start.Line),
+				fmt.Sprintf("col=%d", v.Range.Start.Column),
+				fmt.Sprintf("endLine=%d", v.Range.End.Line),
+				fmt.Sprintf("endColumn=%d", v.Range.End.Column),
+			)
+		}
+
+		title := v.RuleID
+		if v.Environment != "" {
+			title += " (" + v.Environment + ")"
+		}
+		props = append(props, "title="+escapeAnnotationProperty(title))
+
+		message := v.Message
+		if v.Resource != "" {
+			message = v.Resource + ": " + message
+		}
+		if v.Remediation != "" {
+			message += "\nRemediation: " + v.Remediation
+		}
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", annotationLevel(v.Severity), strings.Join(props, ","), escapeAnnotationData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func annotationLevel(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// escapeAnnotationData escapes a workflow command's message the way the
+// Actions toolkit does.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command's property value, which
+// additionally may not contain the property separators.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,295 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "IAM-001",
+		description: "IAM policy documents must not allow every action, every action of a service or, outside the allowlist, every resource",
+		severity:    SeverityError,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkPolicyDocuments(ws, checkPolicyWildcards)
+		},
+	})
+
+	Register(&goRule{
+		id:          "IAM-002",
+		description: "IAM policy statements allowing iam:PassRole must limit the services the role is passed to",
+		severity:    SeverityError,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkPolicyDocuments(ws, checkPassRoleConditions)
+		},
+	})
+}
+
+// checkPolicyDocuments checks every instance of every aws_iam_policy_document
+// with the values each environment gives the configuration, or with the
+// variables' defaults when there are no environments. A policy grants the
+// same access whichever environment it is checked for, so each finding is
+// reported once, naming the environments it is found in unless it is found
+// in all of them.
+func checkPolicyDocuments(ws *Workspace, check func(block *Block, ev *Evaluator, address string) []Violation) []Violation {
+	environments, err := ws.Environments()
+	if err != nil {
+		return []Violation{errorViolation(err)}
+	}
+	views := []*Workspace{ws.WithEnvironment("")}
+	if len(environments) > 0 {
+		views = views[:0]
+		for _, env := range environments {
+			views = append(views, ws.WithEnvironment(env))
+		}
+	}
+
+	type finding struct {
+		violation    Violation
+		environments []string
+	}
+	var findings []*finding
+	for _, view := range views {
+		violations := checkBlocks(view, view.DataSources("aws_iam_policy_document"), func(block *Block) []Violation {
+			return checkInstances(view, block, func(ev *Evaluator, address string) []Violation {
+				return check(block, ev, address)
+			})
+		})
+		for _, v := range violations {
+			i := slices.IndexFunc(findings, func(f *finding) bool {
+				return f.violation.Range == v.Range && f.violation.Resource == v.Resource && f.violation.Message == v.Message
+			})
+			if i < 0 {
+				findings = append(findings, &finding{violation: v})
+				i = len(findings) - 1
+			}
+			if !slices.Contains(findings[i].environments, view.Environment) {
+				findings[i].environments = append(findings[i].environments, view.Environment)
+			}
+		}
+	}
+
+	violations := make([]Violation, 0, len(findings))
+	for _, f := range findings {
+		if len(f.environments) < len(views) {
+			f.violation.Message += " in " + strings.Join(f.environments, ", ")
+		}
+		violations = append(violations, f.violation)
+	}
+	return violations
+}
+
+// wildcardResourceAllowlist names the statements, by sid, that may allow
+// actions on every resource, and the only actions they may allow that way.
+// An entry belongs here only when its actions do not support resource-level
+// permissions, which the comment on it must say.
+var wildcardResourceAllowlist = map[string][]string{
+	// ecr:GetAuthorizationToken returns a registry login for the whole
+	// account and accepts no resource but "*".
+	"ECRGetAuth": {"ecr:GetAuthorizationToken"},
+}
+
+// policyStatement is one statement block of an aws_iam_policy_document,
+// with the values of its attributes in one module instance. Elements only
+// known after apply are left out.
+type policyStatement struct {
+	block   *hclsyntax.Block
+	sid     string
+	allow   bool
+	actions []string
+	// notActions is set when the statement has not_actions, which makes it
+	// apply to every action not listed there instead of those in actions.
+	notActions []string
+	resources  []string
+}
+
+// policyStatements returns the statements of a policy document. A statement
+// whose effect is not known is taken to allow, the worse of the two.
+func policyStatements(block *Block, ev *Evaluator) []policyStatement {
+	var statements []policyStatement
+	for _, stmt := range block.Body.Blocks {
+		if stmt.Type != "statement" {
+			continue
+		}
+
+		statement := policyStatement{block: stmt, allow: true}
+		if attr, ok := stmt.Body.Attributes["sid"]; ok {
+			val, _ := ev.Value(attr.Expr)
+			statement.sid, _ = knownString(val)
+		}
+		if attr, ok := stmt.Body.Attributes["effect"]; ok {
+			val, _ := ev.Value(attr.Expr)
+			if effect, ok := knownString(val); ok {
+				statement.allow = effect != "Deny"
+			}
+		}
+		statement.actions = knownStrings(stmt, ev, "actions")
+		if _, ok := stmt.Body.Attributes["not_actions"]; ok {
+			statement.notActions = append([]string{}, knownStrings(stmt, ev, "not_actions")...)
+		}
+		statement.resources = knownStrings(stmt, ev, "resources")
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// knownStrings returns the known string elements of the statement's list
+// attribute name.
+func knownStrings(stmt *hclsyntax.Block, ev *Evaluator, name string) []string {
+	attr, ok := stmt.Body.Attributes[name]
+	if !ok {
+		return nil
+	}
+	val, diags := ev.Value(attr.Expr)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.CanIterateElements() {
+		return nil
+	}
+
+	var values []string
+	for it := val.ElementIterator(); it.Next(); {
+		_, elem := it.Element()
+		if s, ok := knownString(elem); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// name identifies the statement in messages.
+func (s policyStatement) name() string {
+	if s.sid == "" {
+		return "statement"
+	}
+	return "statement " + s.sid
+}
+
+// violation reports a finding at the statement's attribute, or at the
+// statement itself when it has no such attribute.
+func (s policyStatement) violation(attribute string, address string, message string, remediation string) Violation {
+	rng := s.block.DefRange()
+	if attr, ok := s.block.Body.Attributes[attribute]; ok {
+		rng = attr.Range()
+	}
+	return newViolation(rng, address, message, remediation)
+}
+
+// allows reports whether the statement allows action, matching its action
+// patterns the way IAM does: case-insensitively, with * and ? wildcards. A
+// statement with not_actions allows every action it does not list.
+func (s policyStatement) allows(action string) bool {
+	if !s.allow {
+		return false
+	}
+	if s.notActions != nil {
+		return !matchesAction(s.notActions, action)
+	}
+	return matchesAction(s.actions, action)
+}
+
+func matchesAction(patterns []string, action string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(action))
+		return err == nil && matched
+	})
+}
+
+func checkPolicyWildcards(block *Block, ev *Evaluator, address string) []Violation {
+	const (
+		actionRemediation   = "list the actions the statement needs instead of a wildcard"
+		resourceRemediation = "limit resources to the ARNs the actions need, or, if the actions do not support resource-level permissions, add the statement to the wildcard resource allowlist with the reason"
+	)
+
+	var violations []Violation
+	for _, statement := range policyStatements(block, ev) {
+		if !statement.allow {
+			continue
+		}
+
+		// Whatever they leave out, not_actions and not_resources allow
+		// everything else, including whatever AWS adds later.
+		if _, ok := statement.block.Body.Attributes["not_actions"]; ok {
+			violations = append(violations, statement.violation("not_actions", address, fmt.Sprintf("%s allows every action not listed in not_actions", statement.name()), "list the actions the statement needs in actions instead of not_actions"))
+		}
+		if _, ok := statement.block.Body.Attributes["not_resources"]; ok {
+			violations = append(violations, statement.violation("not_resources", address, fmt.Sprintf("%s allows its actions on every resource not listed in not_resources", statement.name()), "list the resources the statement allows in resources instead of not_resources"))
+		}
+
+		for _, action := range statement.actions {
+			switch service, wildcard := strings.CutSuffix(action, ":*"); {
+			case action == "*":
+				violations = append(violations, statement.violation("actions", address, fmt.Sprintf("%s allows every action (\"*\")", statement.name()), actionRemediation))
+			case wildcard:
+				violations = append(violations, statement.violation("actions", address, fmt.Sprintf("%s allows every %s action (%q)", statement.name(), service, action), actionRemediation))
+			}
+		}
+
+		if !slices.Contains(statement.resources, "*") {
+			continue
+		}
+		allowed, listed := wildcardResourceAllowlist[statement.sid]
+		if listed && len(statement.actions) > 0 && !slices.ContainsFunc(statement.actions, func(action string) bool {
+			return !slices.Contains(allowed, action)
+		}) {
+			continue
+		}
+		actions := strings.Join(statement.actions, ", ")
+		if actions == "" {
+			actions = "its actions"
+		}
+		violations = append(violations, statement.violation("resources", address, fmt.Sprintf("%s allows %s on every resource (\"*\")", statement.name(), actions), resourceRemediation))
+	}
+	return violations
+}
+
+func checkPassRoleConditions(block *Block, ev *Evaluator, address string) []Violation {
+	const remediation = "add condition { test = \"StringEquals\", variable = \"iam:PassedToService\", values = [the service that assumes the role] }"
+
+	var violations []Violation
+	for _, statement := range policyStatements(block, ev) {
+		if !statement.allows("iam:PassRole") || limitsPassedToService(statement.block, ev) {
+			continue
+		}
+		violations = append(violations, newViolation(statement.block.DefRange(), address, fmt.Sprintf("%s allows iam:PassRole without an iam:PassedToService condition naming the services the role may be passed to", statement.name()), remediation))
+	}
+	return violations
+}
+
+// limitsPassedToService reports whether the statement has a StringEquals or
+// StringLike condition on iam:PassedToService with values that name a
+// service.
+func limitsPassedToService(stmt *hclsyntax.Block, ev *Evaluator) bool {
+	for _, condition := range stmt.Body.Blocks {
+		if condition.Type != "condition" {
+			continue
+		}
+
+		variable, ok := condition.Body.Attributes["variable"]
+		if !ok {
+			continue
+		}
+		val, _ := ev.Value(variable.Expr)
+		if name, ok := knownString(val); !ok || !strings.EqualFold(name, "iam:PassedToService") {
+			continue
+		}
+		test, ok := condition.Body.Attributes["test"]
+		if !ok {
+			continue
+		}
+		val, _ = ev.Value(test.Expr)
+		if operator, ok := knownString(val); !ok || operator != "StringEquals" && operator != "StringLike" {
+			continue
+		}
+
+		values := knownStrings(condition, ev, "values")
+		if len(values) > 0 && !slices.ContainsFunc(values, func(value string) bool {
+			return strings.Trim(value, "*?") == ""
+		}) {
+			return true
+		}
+	}
+	return false
+}
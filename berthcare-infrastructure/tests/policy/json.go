@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// jsonSchemaVersion is bumped whenever a field of the JSON report is removed
+// or changes meaning. Adding fields does not change the version.
+const jsonSchemaVersion = 1
+
+type jsonReport struct {
+	SchemaVersion int               `json:"schema_version"`
+	Tool          string            `json:"tool"`
+	Root          string            `json:"root"`
+	Violations    []jsonViolation   `json:"violations"`
+	Suppressions  []jsonSuppression `json:"suppressions"`
+	Summary       jsonSummary       `json:"summary"`
+}
+
+type jsonSuppression struct {
+	Rules      []string  `json:"rules"`
+	Reason     string    `json:"reason"`
+	Expires    string    `json:"expires"`
+	Location   *jsonSpan `json:"location"`
+	Suppressed int       `json:"suppressed"`
+}
+
+type jsonViolation struct {
+	Rule        string    `json:"rule"`
+	Severity    Severity  `json:"severity"`
+	Environment string    `json:"environment"`
+	Resource    string    `json:"resource"`
+	Location    *jsonSpan `json:"location"`
+	Message     string    `json:"message"`
+	Remediation string    `json:"remediation"`
+}
+
+// jsonSpan locates a violation. Line and column are zero when the violation
+// is tied to a file as a whole.
+type jsonSpan struct {
+	File        string `json:"file"`
+	StartLine   int    `json:"start_line"`
+	StartColumn int    `json:"start_column"`
+	EndLine     int    `json:"end_line"`
+	EndColumn   int    `json:"end_column"`
+}
+
+type jsonSummary struct {
+	Rules        int      `json:"rules"`
+	Environments []string `json:"environments"`
+	Violations   int      `json:"violations"`
+	Suppressed   int      `json:"suppressed"`
+	// Baselined and Fixed are only present when a baseline was applied.
+	Baselined  *int             `json:"baselined,omitempty"`
+	Fixed      *int             `json:"fixed,omitempty"`
+	BySeverity map[Severity]int `json:"by_severity"`
+	ByRule     map[string]int   `json:"by_rule"`
+}
+
+func init() {
+	RegisterReporter(&reporterFunc{name: "json", write: writeJSON})
+}
+
+func writeJSON(w io.Writer, report *Report) error {
+	violations := report.Violations()
+
+	out := jsonReport{
+		SchemaVersion: jsonSchemaVersion,
+		Tool:          toolName,
+		Root:          report.Root,
+		Violations:    make([]jsonViolation, 0, len(violations)),
+		Suppressions:  make([]jsonSuppression, 0, len(report.Suppressions)),
+		Summary: jsonSummary{
+			Rules:        len(report.Rules),
+			Environments: report.Environments(),
+			Violations:   len(violations),
+			Suppressed:   len(report.Suppressed()),
+			BySeverity:   map[Severity]int{SeverityError: 0, SeverityWarning: 0, SeverityInfo: 0},
+			ByRule:       map[string]int{},
+		},
+	}
+
+	for _, v := range violations {
+		jv := jsonViolation{
+			Rule:        v.RuleID,
+			Severity:    v.Severity,
+			Environment: v.Environment,
+			Resource:    v.Resource,
+			Message:     v.Message,
+			Remediation: v.Remediation,
+		}
+		if v.Range.Filename != "" {
+			jv.Location = newJSONSpan(v.Range)
+		}
+		out.Violations = append(out.Violations, jv)
+
+		out.Summary.BySeverity[v.Severity]++
+		out.Summary.ByRule[v.RuleID]++
+	}
+
+	if report.Baseline != nil {
+		baselined, fixed := len(report.Baselined()), len(report.Fixed)
+		out.Summary.Baselined, out.Summary.Fixed = &baselined, &fixed
+	}
+
+	for _, s := range report.Suppressions {
+		out.Suppressions = append(out.Suppressions, jsonSuppression{
+			Rules:      s.Rules,
+			Reason:     s.Reason,
+			Expires:    s.Expires.Format(time.DateOnly),
+			Location:   newJSONSpan(s.Range),
+			Suppressed: len(report.SuppressedBy(s)),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func newJSONSpan(rng hcl.Range) *jsonSpan {
+	return &jsonSpan{
+		File:        rng.Filename,
+		StartLine:   rng.Start.Line,
+		StartColumn: rng.Start.Column,
+		EndLine:     rng.End.Line,
+		EndColumn:   rng.End.Column,
+	}
+}
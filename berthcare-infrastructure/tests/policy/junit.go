@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitWorkspaceSuite names the suite holding workspace-scoped rules.
+const junitWorkspaceSuite = "workspace"
+
+func init() {
+	RegisterReporter(&reporterFunc{name: "junit", write: writeJUnit})
+}
+
+// writeJUnit writes the report as JUnit XML with one test suite per
+// environment and one test case per rule evaluated for it. A rule that
+// reported violations fails with every violation in the failure body.
+func writeJUnit(w io.Writer, report *Report) error {
+	out := junitTestSuites{Name: toolName}
+
+	suites := map[string]int{}
+	for _, result := range report.Results {
+		name := result.Environment
+		if name == "" {
+			name = junitWorkspaceSuite
+		}
+
+		index, ok := suites[name]
+		if !ok {
+			index = len(out.Suites)
+			suites[name] = index
+			out.Suites = append(out.Suites, junitTestSuite{Name: name})
+		}
+		suite := &out.Suites[index]
+
+		tc := junitTestCase{
+			Name:      result.Rule.ID() + ": " + result.Rule.Description(),
+			ClassName: toolName + "." + name,
+		}
+		if len(result.Violations) > 0 {
+			lines := make([]string, 0, len(result.Violations))
+			for _, v := range result.Violations {
+				lines = append(lines, v.String())
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d violation(s)", len(result.Violations)),
+				Type:    string(result.Rule.Severity()),
+				Body:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+			out.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		out.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,156 @@
+package policy
+
+import (
+	"path"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth bounds how far module calls are followed so that a module
+// sourcing one of its ancestors cannot recurse forever.
+const maxModuleDepth = 16
+
+// ModuleInstance is a module as instantiated from the root module: either the
+// root itself or a module block followed into its local source directory.
+type ModuleInstance struct {
+	// Name is the module call's label, empty for the root module.
+	Name string
+	// Dir is the module's directory relative to the workspace root.
+	Dir      string
+	Call     *Block
+	Parent   *ModuleInstance
+	Children []*ModuleInstance
+}
+
+// Address returns the instance's module address, such as module.ecs. The
+// root module has an empty address.
+func (m *ModuleInstance) Address() string {
+	if m.Name == "" {
+		return ""
+	}
+	if m.Parent == nil || m.Parent.Address() == "" {
+		return "module." + m.Name
+	}
+	return m.Parent.Address() + ".module." + m.Name
+}
+
+// Child returns the instance created by the named module call.
+func (m *ModuleInstance) Child(name string) *ModuleInstance {
+	for _, child := range m.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// detached reports whether the instance stands for a module directory that
+// no module call reaches, so its variables have no caller to bind to.
+func (m *ModuleInstance) detached() bool {
+	return m.Call == nil && m.Dir != "."
+}
+
+func (m *ModuleInstance) qualify(address string) string {
+	if prefix := m.Address(); prefix != "" {
+		return prefix + "." + address
+	}
+	return address
+}
+
+// RootModule returns the root of the module call tree.
+func (ws *Workspace) RootModule() *ModuleInstance {
+	return ws.index.root
+}
+
+// Instances returns every instance of the module in dir. A directory no
+// module call reaches yields a single detached instance whose variables are
+// left unbound.
+func (ws *Workspace) Instances(dir string) []*ModuleInstance {
+	if instances := ws.index.instances[dir]; len(instances) > 0 {
+		return instances
+	}
+	return []*ModuleInstance{{Dir: dir}}
+}
+
+func (ws *Workspace) buildModuleTree() {
+	ws.index.instances = map[string][]*ModuleInstance{}
+	ws.index.root = &ModuleInstance{Dir: "."}
+	ws.addInstance(ws.index.root, 0)
+
+	// Give directories no module call reaches one detached instance each, so
+	// that every lookup of them returns the same instance.
+	for _, file := range ws.Files {
+		if len(ws.index.instances[file.Module]) == 0 {
+			ws.index.instances[file.Module] = []*ModuleInstance{{Dir: file.Module}}
+		}
+	}
+}
+
+func (ws *Workspace) addInstance(inst *ModuleInstance, depth int) {
+	ws.index.instances[inst.Dir] = append(ws.index.instances[inst.Dir], inst)
+
+	if depth >= maxModuleDepth {
+		return
+	}
+
+	for _, call := range ws.Blocks("module") {
+		if call.Module != inst.Dir || len(call.Labels) != 1 {
+			continue
+		}
+
+		dir, ok := localModuleSource(inst.Dir, call)
+		if !ok || inst.hasAncestorDir(dir) {
+			continue
+		}
+
+		child := &ModuleInstance{
+			Name:   call.Labels[0],
+			Dir:    dir,
+			Call:   call,
+			Parent: inst,
+		}
+		inst.Children = append(inst.Children, child)
+		ws.addInstance(child, depth+1)
+	}
+}
+
+func (m *ModuleInstance) hasAncestorDir(dir string) bool {
+	for inst := m; inst != nil; inst = inst.Parent {
+		if inst.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// localModuleSource resolves a module block's source when it points at a
+// directory in this repository. Registry and git sources are not followed.
+func localModuleSource(callerDir string, call *Block) (string, bool) {
+	attr, ok := call.Body.Attributes["source"]
+	if !ok {
+		return "", false
+	}
+
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() || val.Type() != cty.String {
+		return "", false
+	}
+
+	source := val.AsString()
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return "", false
+	}
+
+	return path.Clean(path.Join(callerDir, source)), true
+}
+
+// moduleBlock returns the block with the given address declared in dir.
+func (ws *Workspace) moduleBlock(dir string, address string) *Block {
+	for _, block := range ws.Lookup(address) {
+		if block.Module == dir {
+			return block
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Mutant is a security-relevant change to one block of a configuration,
+// such as making a database publicly accessible, that at least one rule
+// ought to catch. A mutant no rule catches shows a gap in the rule set.
+type Mutant struct {
+	// Operator names the kind of change, such as "public-database".
+	Operator string
+	// Resource is the address of the changed block within its module.
+	Resource    string
+	Filename    string
+	Description string
+
+	edit *Fix
+}
+
+func (m Mutant) String() string {
+	return fmt.Sprintf("%s %s: %s", m.Operator, m.Resource, m.Description)
+}
+
+// MutationResult is what the rules made of one mutant.
+type MutationResult struct {
+	Mutant Mutant
+	// KilledBy lists, sorted, the rules that reported a violation the
+	// configuration does not have without the mutant. The mutant survived
+	// when it is empty.
+	KilledBy []string
+}
+
+// Killed reports whether any rule caught the mutant.
+func (r MutationResult) Killed() bool {
+	return len(r.KilledBy) > 0
+}
+
+// mutationOperator changes blocks of one kind the way a careless or
+// malicious edit might.
+type mutationOperator struct {
+	name   string
+	blocks func(ws *Workspace) []*Block
+	// mutate returns the changes it makes to block, none when it does not
+	// apply.
+	mutate func(block *Block) []*Fix
+}
+
+var mutationOperators = []mutationOperator{
+	{"public-database", resources("aws_db_instance"), setAttribute(nil, "publicly_accessible", cty.True)},
+	{"unencrypted-database", resources("aws_db_instance"), setAttribute(nil, "storage_encrypted", cty.False)},
+	{"short-backup-retention", resources("aws_db_instance"), setAttribute(nil, "backup_retention_period", cty.NumberIntVal(1))},
+	{"open-postgres-ingress", resources("aws_security_group"), openPostgresIngress},
+	{"weak-tls-policy", resources("aws_lb_listener"), whenAttribute("ssl_policy", setAttribute(nil, "ssl_policy", cty.StringVal("ELBSecurityPolicy-2016-08")))},
+	{"plain-http-redirect", resources("aws_lb_listener"), whenBlock([]string{"default_action", "redirect"}, setAttribute([]string{"default_action", "redirect"}, "protocol", cty.StringVal("HTTP")))},
+	{"public-bucket", resources("aws_s3_bucket_public_access_block"), disablePublicAccessBlock},
+	{"unencrypted-bucket", resources("aws_s3_bucket"), removeBlock("server_side_encryption_configuration")},
+	{"unversioned-bucket", resources("aws_s3_bucket"), setAttribute([]string{"versioning"}, "enabled", cty.False)},
+	{"open-bucket-policy", bucketPolicies, setAttribute([]string{"statement", "principals"}, "identifiers", cty.TupleVal([]cty.Value{cty.StringVal("*")}))},
+	{"public-private-subnet", resources("aws_subnet"), setAttribute(nil, "map_public_ip_on_launch", cty.True)},
+	{"email-certificate-validation", resources("aws_acm_certificate"), setAttribute(nil, "validation_method", cty.StringVal("EMAIL"))},
+	{"unhealthy-alias", resources("aws_route53_record"), whenBlock([]string{"alias"}, setAttribute([]string{"alias"}, "evaluate_target_health", cty.False))},
+	{"foreign-provider-region", providers("aws"), setAttribute(nil, "region", cty.StringVal("us-east-1"))},
+	{"foreign-backend-region", terraformBlocks, setBackendAttribute("region", cty.StringVal("us-east-1"))},
+	{"unencrypted-state-backend", terraformBlocks, setBackendAttribute("encrypt", cty.False)},
+}
+
+func resources(resourceType string) func(ws *Workspace) []*Block {
+	return func(ws *Workspace) []*Block { return ws.Resources(resourceType) }
+}
+
+func providers(name string) func(ws *Workspace) []*Block {
+	return func(ws *Workspace) []*Block { return ws.Providers(name) }
+}
+
+func terraformBlocks(ws *Workspace) []*Block {
+	return ws.Blocks("terraform")
+}
+
+func bucketPolicies(ws *Workspace) []*Block {
+	var blocks []*Block
+	for _, block := range ws.DataSources("aws_iam_policy_document") {
+		if block.Labels[1] == "photos" || block.Labels[1] == "exports" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func setAttribute(path []string, name string, val cty.Value) func(block *Block) []*Fix {
+	return func(block *Block) []*Fix {
+		return []*Fix{setAttributeFix(block, path, name, val)}
+	}
+}
+
+// whenAttribute applies mutate only to blocks that set the attribute.
+func whenAttribute(name string, mutate func(block *Block) []*Fix) func(block *Block) []*Fix {
+	return func(block *Block) []*Fix {
+		if _, ok := block.Body.Attributes[name]; !ok {
+			return nil
+		}
+		return mutate(block)
+	}
+}
+
+// whenBlock applies mutate only to blocks with a nested block reached by
+// following the block types in path.
+func whenBlock(path []string, mutate func(block *Block) []*Fix) func(block *Block) []*Fix {
+	return func(block *Block) []*Fix {
+		body := block.Body
+		for _, blockType := range path {
+			i := slices.IndexFunc(body.Blocks, func(nested *hclsyntax.Block) bool { return nested.Type == blockType })
+			if i < 0 {
+				return nil
+			}
+			body = body.Blocks[i].Body
+		}
+		return mutate(block)
+	}
+}
+
+func removeBlock(blockType string) func(block *Block) []*Fix {
+	return whenBlock([]string{blockType}, func(block *Block) []*Fix {
+		return []*Fix{blockFix(block, "remove "+blockType, func(body *hclwrite.Body) {
+			body.RemoveBlock(body.FirstMatchingBlock(blockType, nil))
+		})}
+	})
+}
+
+// disablePublicAccessBlock turns off each protection of a public access
+// block in turn.
+func disablePublicAccessBlock(block *Block) []*Fix {
+	fixes := make([]*Fix, 0, len(publicAccessBlockAttributes))
+	for _, name := range publicAccessBlockAttributes {
+		fixes = append(fixes, setAttributeFix(block, nil, name, cty.False))
+	}
+	return fixes
+}
+
+// openPostgresIngress opens the postgres ingress rules of a security group
+// to the internet.
+func openPostgresIngress(block *Block) []*Fix {
+	isPostgres := func(ingress *hclsyntax.Block) bool {
+		from, ok := ingress.Body.Attributes["from_port"]
+		return ingress.Type == "ingress" && ok && isConstNumber(from, 5432)
+	}
+	if !slices.ContainsFunc(block.Body.Blocks, isPostgres) {
+		return nil
+	}
+
+	return []*Fix{blockFix(block, `set ingress.cidr_blocks = ["0.0.0.0/0"] on port 5432`, func(body *hclwrite.Body) {
+		for _, ingress := range body.Blocks() {
+			from := ingress.Body().GetAttribute("from_port")
+			if ingress.Type() == "ingress" && from != nil && string(bytes.TrimSpace(from.Expr().BuildTokens(nil).Bytes())) == "5432" {
+				ingress.Body().SetAttributeValue("cidr_blocks", cty.TupleVal([]cty.Value{cty.StringVal("0.0.0.0/0")}))
+			}
+		}
+	})}
+}
+
+// setBackendAttribute sets an attribute of the s3 backend of a terraform
+// block.
+func setBackendAttribute(name string, val cty.Value) func(block *Block) []*Fix {
+	return func(block *Block) []*Fix {
+		hasBackend := slices.ContainsFunc(block.Body.Blocks, func(nested *hclsyntax.Block) bool {
+			return nested.Type == "backend" && slices.Equal(nested.Labels, []string{"s3"})
+		})
+		if !hasBackend {
+			return nil
+		}
+
+		description := fmt.Sprintf("set backend.s3.%s = %s", name, formatValue(val))
+		return []*Fix{blockFix(block, description, func(body *hclwrite.Body) {
+			if backend := body.FirstMatchingBlock("backend", []string{"s3"}); backend != nil {
+				backend.Body().SetAttributeValue(name, val)
+			}
+		})}
+	}
+}
+
+// Mutants returns every mutant of the configuration in ws, ordered by
+// operator and then by block. Some may leave the configuration as it was,
+// such as turning off a protection that is already off.
+func Mutants(ws *Workspace) []Mutant {
+	var mutants []Mutant
+	for _, operator := range mutationOperators {
+		for _, block := range operator.blocks(ws) {
+			for _, edit := range operator.mutate(block) {
+				mutants = append(mutants, Mutant{
+					Operator:    operator.name,
+					Resource:    block.Address,
+					Filename:    block.File.Path,
+					Description: edit.Description,
+					edit:        edit,
+				})
+			}
+		}
+	}
+	return mutants
+}
+
+// RunMutations applies each mutant of the configuration at root on its own,
+// in memory, evaluates the selected rules against the result in every
+// environment and reports which rules caught it. Mutants that leave the
+// configuration as it was are skipped.
+func (e *Engine) RunMutations(root string) ([]MutationResult, error) {
+	ws, err := LoadWorkspace(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(ws.Diagnostics()) > 0 {
+		return nil, fmt.Errorf("%s: cannot mutate Terraform files that fail to parse", root)
+	}
+
+	known, err := e.fingerprints(root, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MutationResult
+	for _, mutant := range Mutants(ws) {
+		content, err := os.ReadFile(mutant.Filename)
+		if err != nil {
+			return nil, err
+		}
+		original, err := editFile(mutant.Filename, content, nil)
+		if err != nil {
+			return nil, err
+		}
+		mutated, err := editFile(mutant.Filename, content, []*Fix{mutant.edit})
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(mutated, original) {
+			continue
+		}
+
+		mutatedWS, err := loadWorkspace(root, map[string][]byte{mutant.Filename: mutated})
+		if err != nil {
+			return nil, err
+		}
+		found, err := e.fingerprints(root, mutatedWS)
+		if err != nil {
+			return nil, err
+		}
+
+		result := MutationResult{Mutant: mutant}
+		for fingerprint, rule := range found {
+			if _, ok := known[fingerprint]; !ok && !slices.Contains(result.KilledBy, rule) {
+				result.KilledBy = append(result.KilledBy, rule)
+			}
+		}
+		slices.Sort(result.KilledBy)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// fingerprints evaluates the selected rules against ws in every environment
+// and returns the rule of each violation by fingerprint.
+func (e *Engine) fingerprints(root string, ws *Workspace) (map[string]string, error) {
+	results, err := e.RunMatrix(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]string{}
+	for _, result := range results {
+		for _, v := range result.Violations {
+			found[Fingerprint(root, v)] = v.RuleID
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,512 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// The plan types cover the parts of Terraform's JSON plan format, as written
+// by terraform show -json, that LoadPlan reads.
+type planJSON struct {
+	FormatVersion   string                  `json:"format_version"`
+	Variables       map[string]planVariable `json:"variables"`
+	PlannedValues   planValues              `json:"planned_values"`
+	ResourceChanges []planResourceChange    `json:"resource_changes"`
+	Configuration   planConfiguration       `json:"configuration"`
+}
+
+type planVariable struct {
+	Value any `json:"value"`
+}
+
+type planValues struct {
+	RootModule planModule `json:"root_module"`
+}
+
+type planModule struct {
+	Address      string         `json:"address"`
+	Resources    []planResource `json:"resources"`
+	ChildModules []planModule   `json:"child_modules"`
+}
+
+type planResource struct {
+	Address string         `json:"address"`
+	Mode    string         `json:"mode"`
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Index   any            `json:"index"`
+	Values  map[string]any `json:"values"`
+}
+
+type planResourceChange struct {
+	Address string     `json:"address"`
+	Mode    string     `json:"mode"`
+	Type    string     `json:"type"`
+	Change  planChange `json:"change"`
+}
+
+type planChange struct {
+	Actions      []string `json:"actions"`
+	AfterUnknown any      `json:"after_unknown"`
+}
+
+// ResourceChange is the action a plan takes on one resource instance.
+type ResourceChange struct {
+	Address string
+	Mode    string
+	Type    string
+	// Actions is the plan's action list, such as ["create"], ["delete"] or
+	// ["delete", "create"] for a replacement.
+	Actions []string
+}
+
+// Destroys reports whether the change destroys the existing object, by
+// deleting or replacing it.
+func (c ResourceChange) Destroys() bool {
+	return c.Mode == "managed" && slices.Contains(c.Actions, "delete")
+}
+
+// Replaces reports whether the change destroys the object and creates a new
+// one in its place.
+func (c ResourceChange) Replaces() bool {
+	return c.Destroys() && slices.Contains(c.Actions, "create")
+}
+
+type planConfiguration struct {
+	ProviderConfig map[string]planProviderConfig `json:"provider_config"`
+	RootModule     planConfigModule              `json:"root_module"`
+}
+
+type planProviderConfig struct {
+	Name          string         `json:"name"`
+	Alias         string         `json:"alias"`
+	ModuleAddress string         `json:"module_address"`
+	Expressions   map[string]any `json:"expressions"`
+}
+
+type planConfigModule struct {
+	Resources   []planConfigResource      `json:"resources"`
+	ModuleCalls map[string]planModuleCall `json:"module_calls"`
+}
+
+type planModuleCall struct {
+	Module planConfigModule `json:"module"`
+}
+
+type planConfigResource struct {
+	Mode        string         `json:"mode"`
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Expressions map[string]any `json:"expressions"`
+}
+
+// LoadPlan reads a plan in Terraform's JSON format, as written by terraform
+// show -json, into a workspace that rules evaluate like a configuration.
+//
+// Every resource instance in planned_values becomes a resource block in its
+// module whose attributes are the planned values, so computed values,
+// for_each and count expansions and values passed between modules are all
+// resolved. Lists of objects become nested blocks. Attributes resource_changes
+// marks as unknown until apply are written as the reference their
+// configuration expression makes, or failing that a reference to the
+// attribute itself, so they evaluate to unknown. Root provider blocks are
+// written from their configuration expressions and root variables take the
+// plan's values as defaults.
+//
+// Only rules that support plans are evaluated against the workspace, and
+// their findings are located at the plan file, identified by the planned
+// instance's address.
+func LoadPlan(path string) (*Workspace, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan planJSON
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !strings.HasPrefix(plan.FormatVersion, "1.") {
+		return nil, fmt.Errorf("%s: unsupported plan format version %q", path, plan.FormatVersion)
+	}
+
+	ws := &Workspace{
+		Root:         filepath.Dir(path),
+		DestroyGuard: DefaultDestroyGuard.clone(),
+		index: &index{
+			byKind:          map[string][]*Block{},
+			byAddress:       map[string][]*Block{},
+			attributes:      map[string]attributeFile{},
+			evaluations:     map[string]*evaluation{},
+			instances:       map[string][]*ModuleInstance{},
+			plan:            path,
+			renderedModules: map[string]string{},
+		},
+	}
+
+	unknown := map[string]any{}
+	for _, rc := range plan.ResourceChanges {
+		unknown[rc.Address] = rc.Change.AfterUnknown
+		ws.index.changes = append(ws.index.changes, ResourceChange{Address: rc.Address, Mode: rc.Mode, Type: rc.Type, Actions: rc.Change.Actions})
+	}
+
+	r := &planRenderer{unknown: unknown}
+
+	ws.index.root = &ModuleInstance{Dir: "."}
+	root := r.renderModule(plan.PlannedValues.RootModule, &plan.Configuration.RootModule)
+	r.renderVariables(root, plan.Variables)
+	r.renderProviders(root, plan.Configuration.ProviderConfig)
+	if err := ws.addRenderedModule(ws.index.root, root); err != nil {
+		return nil, err
+	}
+
+	if err := ws.addPlanChildren(ws.index.root, plan.PlannedValues.RootModule.ChildModules, r, &plan.Configuration.RootModule); err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// PlanFile returns the plan the workspace was loaded from, or "" for a
+// workspace loaded from configuration or state.
+func (ws *Workspace) PlanFile() string {
+	return ws.index.plan
+}
+
+// renderedFile returns the plan or state file the workspace was rendered
+// from, or "" for a workspace loaded from configuration.
+func (ws *Workspace) renderedFile() string {
+	if ws.index.plan != "" {
+		return ws.index.plan
+	}
+	return ws.index.state
+}
+
+// ResourceChanges returns the plan's resource_changes, in plan order. A
+// workspace loaded from configuration has none.
+func (ws *Workspace) ResourceChanges() []ResourceChange {
+	return ws.index.changes
+}
+
+func (ws *Workspace) addPlanChildren(parent *ModuleInstance, modules []planModule, r *planRenderer, config *planConfigModule) error {
+	for _, module := range modules {
+		name := strings.TrimPrefix(strings.TrimPrefix(module.Address, parent.Address()), ".")
+		name = strings.TrimPrefix(name, "module.")
+
+		var childConfig *planConfigModule
+		if config != nil {
+			if call, ok := config.ModuleCalls[moduleCallName(name)]; ok {
+				childConfig = &call.Module
+			}
+		}
+
+		inst := &ModuleInstance{Name: name, Dir: module.Address, Parent: parent}
+		parent.Children = append(parent.Children, inst)
+		if err := ws.addRenderedModule(inst, r.renderModule(module, childConfig)); err != nil {
+			return err
+		}
+		if err := ws.addPlanChildren(inst, module.ChildModules, r, childConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRenderedModule indexes the rendering of one module instance. Each
+// rendering gets its own file name so that findings can be traced back to
+// the module instance they were reported in.
+func (ws *Workspace) addRenderedModule(inst *ModuleInstance, rendered *hclwrite.File) error {
+	filename := ws.renderedFile()
+	if address := inst.Address(); address != "" {
+		filename += "#" + address
+	}
+	ws.index.renderedModules[filename] = inst.Address()
+	ws.index.instances[inst.Dir] = []*ModuleInstance{inst}
+
+	body, violations := parseBody(filename, rendered.Bytes())
+	if body == nil {
+		return fmt.Errorf("%s: rendering resource values: %s", ws.renderedFile(), violations[0].Message)
+	}
+	file := &File{Path: filename, Module: inst.Dir}
+	ws.Files = append(ws.Files, file)
+	ws.addBody(file, body)
+	return nil
+}
+
+// locateRenderedFindings moves findings in a plan or state workspace onto
+// the file as a whole, since the rendered configuration they were found in
+// exists only in memory, and qualifies their addresses with the module
+// instance. Their fixes are dropped for the same reason.
+func (ws *Workspace) locateRenderedFindings(violations []Violation) {
+	for i := range violations {
+		v := &violations[i]
+		if module := ws.index.renderedModules[v.Range.Filename]; module != "" && v.Resource != "" && !strings.HasPrefix(v.Resource, "module.") {
+			v.Resource = module + "." + v.Resource
+		}
+		v.Range = hcl.Range{Filename: ws.renderedFile()}
+		v.Fix = nil
+	}
+}
+
+// moduleCallName strips the instance key from a module instance name such as
+// storage["photos"].
+func moduleCallName(name string) string {
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// planRenderer writes planned values as Terraform configuration.
+type planRenderer struct {
+	// unknown holds each resource instance's after_unknown, by address.
+	unknown map[string]any
+}
+
+func (r *planRenderer) renderModule(module planModule, config *planConfigModule) *hclwrite.File {
+	expressions := map[string]map[string]any{}
+	if config != nil {
+		for _, resource := range config.Resources {
+			expressions[resource.Mode+"."+resource.Type+"."+resource.Name] = resource.Expressions
+		}
+	}
+
+	f := hclwrite.NewEmptyFile()
+	for _, resource := range module.Resources {
+		kind, self := "resource", hcl.Traversal{hcl.TraverseRoot{Name: resource.Type}}
+		if resource.Mode == "data" {
+			kind, self = "data", hcl.Traversal{hcl.TraverseRoot{Name: "data"}, hcl.TraverseAttr{Name: resource.Type}}
+		}
+		self = append(self, hcl.TraverseAttr{Name: resource.Name})
+
+		name := resource.Name
+		switch index := resource.Index.(type) {
+		case string:
+			name += fmt.Sprintf("[%q]", index)
+		case json.Number:
+			name += "[" + index.String() + "]"
+		}
+
+		block := f.Body().AppendNewBlock(kind, []string{resource.Type, name})
+		unknown, _ := r.unknown[resource.Address].(map[string]any)
+		r.renderBody(block.Body(), resource.Values, unknown, expressions[resource.Mode+"."+resource.Type+"."+resource.Name], self)
+	}
+	return f
+}
+
+// renderBody writes values into body. unknown mirrors values with true at
+// every value not known until apply, and expressions holds the configuration
+// expressions the values came from.
+func (r *planRenderer) renderBody(body *hclwrite.Body, values map[string]any, unknown map[string]any, expressions map[string]any, self hcl.Traversal) {
+	names := map[string]bool{}
+	for name := range values {
+		names[name] = true
+	}
+	for name, u := range unknown {
+		if u == true {
+			names[name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(names) {
+		value := values[name]
+
+		if containsUnknown(unknown[name]) && !isObjectList(value) {
+			body.SetAttributeTraversal(name, unknownReference(expressions[name], append(self, hcl.TraverseAttr{Name: name})))
+			continue
+		}
+
+		switch {
+		case value == nil:
+			// Null is the same as unset.
+		case isObjectList(value):
+			unknownBlocks, _ := unknown[name].([]any)
+			exprBlocks, _ := expressions[name].([]any)
+			for i, item := range value.([]any) {
+				nested := body.AppendNewBlock(name, nil)
+				r.renderBody(nested.Body(), item.(map[string]any), mapAt(unknownBlocks, i), mapAt(exprBlocks, i), append(self, hcl.TraverseAttr{Name: name}))
+			}
+		default:
+			body.SetAttributeValue(name, jsonToCty(value))
+		}
+	}
+}
+
+func (r *planRenderer) renderVariables(f *hclwrite.File, variables map[string]planVariable) {
+	for _, name := range sortedKeys(variables) {
+		block := f.Body().AppendNewBlock("variable", []string{name})
+		block.Body().SetAttributeValue("default", jsonToCty(variables[name].Value))
+	}
+}
+
+// renderProviders writes the root module's provider configurations from
+// their configuration expressions; a plan records no provider values.
+func (r *planRenderer) renderProviders(f *hclwrite.File, providers map[string]planProviderConfig) {
+	for _, key := range sortedKeys(providers) {
+		provider := providers[key]
+		if provider.ModuleAddress != "" {
+			continue
+		}
+
+		block := f.Body().AppendNewBlock("provider", []string{provider.Name})
+		if provider.Alias != "" {
+			block.Body().SetAttributeValue("alias", cty.StringVal(provider.Alias))
+		}
+		renderExpressions(block.Body(), provider.Expressions)
+	}
+}
+
+// renderExpressions writes configuration expressions: constants as values,
+// other expressions as the first reference they make, and nested blocks as
+// blocks. Expressions with neither a constant value nor a reference are
+// left out.
+func renderExpressions(body *hclwrite.Body, expressions map[string]any) {
+	for _, name := range sortedKeys(expressions) {
+		switch expr := expressions[name].(type) {
+		case []any:
+			for _, item := range expr {
+				if nested, ok := item.(map[string]any); ok {
+					renderExpressions(body.AppendNewBlock(name, nil).Body(), nested)
+				}
+			}
+		case map[string]any:
+			if value, ok := expr["constant_value"]; ok {
+				body.SetAttributeValue(name, jsonToCty(value))
+			} else if traversal, ok := firstReference(expr); ok {
+				body.SetAttributeTraversal(name, traversal)
+			}
+		}
+	}
+}
+
+// unknownReference returns the reference a value unknown until apply is
+// written as: the first reference its configuration expression makes, or
+// self.
+func unknownReference(expression any, self hcl.Traversal) hcl.Traversal {
+	if expr, ok := expression.(map[string]any); ok {
+		if traversal, ok := firstReference(expr); ok {
+			return traversal
+		}
+	}
+	return self
+}
+
+func firstReference(expr map[string]any) (hcl.Traversal, bool) {
+	references, _ := expr["references"].([]any)
+	if len(references) == 0 {
+		return nil, false
+	}
+	reference, _ := references[0].(string)
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(reference), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	return traversal, true
+}
+
+// containsUnknown reports whether an after_unknown value marks anything as
+// unknown.
+func containsUnknown(u any) bool {
+	switch u := u.(type) {
+	case bool:
+		return u
+	case []any:
+		for _, item := range u {
+			if containsUnknown(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range u {
+			if containsUnknown(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isObjectList reports whether a planned value is a non-empty list of
+// objects, which is how the plan represents nested blocks.
+func isObjectList(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func mapAt(items []any, i int) map[string]any {
+	if i >= len(items) {
+		return nil
+	}
+	m, _ := items[i].(map[string]any)
+	return m
+}
+
+// jsonToCty converts a value decoded from JSON, with numbers kept as
+// json.Number, to a cty value. Arrays become tuples so that their elements
+// may differ in type.
+func jsonToCty(value any) cty.Value {
+	switch value := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType)
+	case bool:
+		return cty.BoolVal(value)
+	case string:
+		return cty.StringVal(value)
+	case json.Number:
+		n, err := cty.ParseNumberVal(value.String())
+		if err != nil {
+			return cty.StringVal(value.String())
+		}
+		return n
+	case []any:
+		if len(value) == 0 {
+			return cty.EmptyTupleVal
+		}
+		items := make([]cty.Value, len(value))
+		for i, item := range value {
+			items[i] = jsonToCty(item)
+		}
+		return cty.TupleVal(items)
+	case map[string]any:
+		if len(value) == 0 {
+			return cty.EmptyObjectVal
+		}
+		attrs := make(map[string]cty.Value, len(value))
+		for name, item := range value {
+			attrs[name] = jsonToCty(item)
+		}
+		return cty.ObjectVal(attrs)
+	default:
+		return cty.DynamicVal
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,264 @@
+package policy
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "RDS-001",
+		description: "RDS instances must encrypt storage at rest",
+		severity:    SeverityError,
+		traces:      []Trace{devRDS.trace("2.2"), stagingRDS.trace("2.2"), setupRDS.trace()},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_db_instance"), func(block *Block) []Violation {
+				return enforceBoolAttr(block, "storage_encrypted", true)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "RDS-002",
+		description: "RDS instances must not be publicly accessible",
+		severity:    SeverityError,
+		traces:      []Trace{devRDS.trace("2.3"), stagingRDS.trace("2.3"), setupRDS.trace()},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_db_instance"), func(block *Block) []Violation {
+				return enforceBoolAttr(block, "publicly_accessible", false)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "RDS-003",
+		description: "RDS instances must retain automated backups for at least 7 days",
+		severity:    SeverityError,
+		traces:      []Trace{devRDS.trace("2.5"), stagingRDS.trace("2.5"), setupRDS.trace()},
+		scope:       ScopeEnvironment,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_db_instance"), func(block *Block) []Violation {
+				return checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+					return enforceBackupRetention(block, ev, address)
+				})
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "RDS-004",
+		description: "DB subnet groups must place RDS in private subnets",
+		severity:    SeverityError,
+		traces:      []Trace{devRDS.trace("2.4"), stagingRDS.trace("2.4")},
+		evaluate: func(ws *Workspace) []Violation {
+			return requireBlocks(ws, ws.Resources("aws_db_subnet_group"), workspaceViolation("expected at least one aws_db_subnet_group to validate", "declare an aws_db_subnet_group using the private subnets"), func(block *Block) []Violation {
+				return checkSubnetPlacement(ws, block, dbSubnetPlacement)
+			})
+		},
+	})
+
+	Register(&goRule{
+		id:          "RDS-005",
+		description: "Postgres ingress must be restricted to the ECS task security group",
+		severity:    SeverityError,
+		traces:      []Trace{stagingRDS.trace("2.1")},
+		evaluate: func(ws *Workspace) []Violation {
+			return evaluateRDSSecurityGroups(ws)
+		},
+	})
+}
+
+func enforceBoolAttr(block *Block, attrName string, expected bool) []Violation {
+	address := block.Address
+	remediation := fmt.Sprintf("set %s = %t", attrName, expected)
+	fix := setAttributeFix(block, nil, attrName, cty.BoolVal(expected))
+
+	attr, ok := block.Body.Attributes[attrName]
+	if !ok {
+		return []Violation{withFix(newViolation(block.DefRange(), address, fmt.Sprintf("aws_db_instance missing %s", attrName), remediation), fix)}
+	}
+
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("%s must be a constant boolean (%s)", attrName, diag.Error()), remediation)}
+	}
+
+	if val.Type() != cty.Bool {
+		return []Violation{withFix(newViolation(attr.Range(), address, fmt.Sprintf("%s must be a boolean literal", attrName), remediation), literalFix(attr.Expr, fix))}
+	}
+
+	if val.True() != expected {
+		return []Violation{withFix(newViolation(attr.Range(), address, fmt.Sprintf("%s must be %t", attrName, expected), remediation), literalFix(attr.Expr, fix))}
+	}
+
+	return nil
+}
+
+func enforceBackupRetention(block *Block, ev *Evaluator, address string) []Violation {
+	const remediation = "set backup_retention_period to 7 or more days"
+
+	attr, ok := block.Body.Attributes["backup_retention_period"]
+	if !ok {
+		return []Violation{newViolation(block.DefRange(), address, "aws_db_instance missing backup_retention_period", remediation)}
+	}
+
+	val, diag := ev.Value(attr.Expr)
+	if diag.HasErrors() {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("backup_retention_period must be a resolvable number (%s)", diag.Error()), remediation)}
+	}
+
+	if !val.IsKnown() || val.IsNull() || !val.Type().Equals(cty.Number) {
+		return []Violation{newViolation(attr.Range(), address, "backup_retention_period must be a number known before apply", remediation)}
+	}
+
+	if val.AsBigFloat().Cmp(big.NewFloat(7)) < 0 {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("backup_retention_period is %s (must be >= 7)", val.AsBigFloat().Text('f', -1)), remediation)}
+	}
+
+	return nil
+}
+
+var dbSubnetPlacement = subnetPlacement{
+	noun:      "db subnet group",
+	attribute: "subnet_ids",
+	variable:  "private_subnet_ids",
+}
+
+func evaluateRDSSecurityGroups(ws *Workspace) []Violation {
+	const remediation = "set security_groups = [var.allowed_security_group_id] and drop CIDR ranges from the postgres ingress"
+
+	var violations []Violation
+	foundRule := false
+
+	for _, block := range ws.Resources("aws_security_group") {
+		if !ws.inFocus(block) {
+			continue
+		}
+		address := block.Address
+
+		for _, nested := range block.Body.Blocks {
+			if nested.Type != "ingress" {
+				continue
+			}
+
+			from, okFrom := nested.Body.Attributes["from_port"]
+			to, okTo := nested.Body.Attributes["to_port"]
+			protocolAttr, okProto := nested.Body.Attributes["protocol"]
+			if !okFrom || !okTo || !okProto {
+				continue
+			}
+
+			if !isConstNumber(from, 5432) || !isConstNumber(to, 5432) || !isConstString(protocolAttr, "tcp") {
+				continue
+			}
+
+			sgAttr, ok := nested.Body.Attributes["security_groups"]
+			if !ok {
+				violations = append(violations, newViolation(nested.DefRange(), address, "postgres ingress must restrict to ECS task security group via security_groups", remediation))
+				continue
+			}
+
+			val, sgDiag := constValue(sgAttr.Expr)
+			if sgDiag.HasErrors() {
+				violations = append(violations, checkIngressSources(ws, block, sgAttr, remediation)...)
+			} else {
+				if !val.Type().IsListType() && !val.Type().IsTupleType() {
+					violations = append(violations, newViolation(sgAttr.Range(), address, "security_groups must be a list of security group IDs", remediation))
+				} else if val.LengthInt() == 0 {
+					violations = append(violations, newViolation(sgAttr.Range(), address, "security_groups must include ECS task security group", remediation))
+				} else {
+					for i := 0; i < val.LengthInt(); i++ {
+						elem := val.Index(cty.NumberIntVal(int64(i)))
+						if _, ok := knownString(elem); !ok {
+							violations = append(violations, newViolation(sgAttr.Range(), address, fmt.Sprintf("security_groups[%d] must be string", i), remediation))
+						}
+					}
+				}
+			}
+
+			if cidrAttr, ok := nested.Body.Attributes["cidr_blocks"]; ok {
+				if val, diag := constValue(cidrAttr.Expr); !diag.HasErrors() && (!val.CanIterateElements() || val.LengthInt() > 0) {
+					violations = append(violations, newViolation(cidrAttr.Range(), address, "postgres ingress should not allow cidr_blocks", remediation))
+				}
+			}
+
+			if cidr6Attr, ok := nested.Body.Attributes["ipv6_cidr_blocks"]; ok {
+				if val, diag := constValue(cidr6Attr.Expr); !diag.HasErrors() && (!val.CanIterateElements() || val.LengthInt() > 0) {
+					violations = append(violations, newViolation(cidr6Attr.Range(), address, "postgres ingress should not allow ipv6_cidr_blocks", remediation))
+				}
+			}
+
+			foundRule = true
+		}
+	}
+
+	if !foundRule {
+		violations = append(violations, workspaceViolation("expected at least one postgres ingress rule tied to ECS tasks", "allow tcp/5432 only from the ECS task security group"))
+	}
+
+	return violations
+}
+
+// checkIngressSources follows a postgres ingress rule's security_groups back
+// to the security groups it admits and reports any that do not belong to the
+// ECS tasks.
+func checkIngressSources(ws *Workspace, block *Block, attr *hclsyntax.Attribute, remediation string) []Violation {
+	var violations []Violation
+	for _, inst := range ws.Instances(block.Module) {
+		address := inst.qualify(block.Address)
+		groups, origins := ws.tracedResources(inst, attr.Expr, "aws_security_group")
+
+		for _, group := range groups {
+			if !isECSTaskSecurityGroup(ws, group.Block) {
+				violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("security_groups traces to %s, which is not the ECS task security group", group), remediation))
+			}
+		}
+		if len(groups) > 0 {
+			continue
+		}
+
+		others, unbound := otherOrigins(origins)
+		switch {
+		case len(others) > 0:
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("security_groups traces to %s instead of a security group", strings.Join(others, ", ")), remediation))
+		case unbound && !isVarReference(attr.Expr, "allowed_security_group_id"):
+			violations = append(violations, newViolation(attr.Range(), address, "security_groups must reference allowed_security_group_id or be a constant list", remediation))
+		}
+	}
+	return violations
+}
+
+// isECSTaskSecurityGroup reports whether group is declared alongside an ECS
+// cluster and is not the group fronting the cluster's load balancer.
+func isECSTaskSecurityGroup(ws *Workspace, group *Block) bool {
+	hasCluster := false
+	for _, cluster := range ws.Resources("aws_ecs_cluster") {
+		if cluster.Module == group.Module {
+			hasCluster = true
+			break
+		}
+	}
+	if !hasCluster {
+		return false
+	}
+
+	for _, lb := range ws.Resources("aws_lb") {
+		if lb.Module != group.Module {
+			continue
+		}
+		if attr, ok := lb.Body.Attributes["security_groups"]; ok && referencesResource(attr.Expr, "aws_security_group", group.Labels[1]) {
+			return false
+		}
+	}
+
+	return true
+}
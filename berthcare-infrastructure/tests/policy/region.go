@@ -0,0 +1,181 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const regionRemediation = "set region = \"" + ExpectedRegion + "\""
+
+func init() {
+	Register(&goRule{
+		id:          "REGION-001",
+		description: "Every region attribute must be set to " + ExpectedRegion,
+		severity:    SeverityError,
+		traces:      []Trace{devRegion.trace("1.1"), stagingRegion.trace("1.1"), setupRegion.trace()},
+		plan:        true,
+		evaluate:    evaluateRegions,
+	})
+
+	Register(&goRule{
+		id:          "REGION-002",
+		description: "The aws provider and the s3 backend must pin region " + ExpectedRegion,
+		severity:    SeverityError,
+		traces:      []Trace{devRegion.trace("1.2"), stagingRegion.trace("1.2"), setupPlanRegion.trace()},
+		evaluate:    evaluateProviderAndBackendRegions,
+	})
+
+	Register(&goRule{
+		id:          "REGION-003",
+		description: "Environment availability zones must be in " + ExpectedRegion,
+		severity:    SeverityError,
+		traces:      []Trace{stagingRegion.trace("1.3")},
+		scope:       ScopeEnvironment,
+		evaluate:    evaluateAvailabilityZones,
+	})
+}
+
+func evaluateRegions(ws *Workspace) []Violation {
+	var violations []Violation
+	for _, file := range ws.Files {
+		if file.Body != nil {
+			walkBodyForRegions(file.Body, "", nil, &violations)
+		}
+	}
+	return violations
+}
+
+func walkBodyForRegions(body *hclsyntax.Body, address string, path []string, violations *[]Violation) {
+	for name, attr := range body.Attributes {
+		if name == "region" {
+			val, diag := constValue(attr.Expr)
+			if diag.HasErrors() {
+				*violations = append(*violations, newViolation(attr.Range(), address, fmt.Sprintf("region must be a constant string (%s)", diag.Error()), regionRemediation))
+				continue
+			}
+
+			if val.Type() != cty.String {
+				*violations = append(*violations, newViolation(attr.Range(), address, "region must be a string literal", regionRemediation))
+				continue
+			}
+
+			region := val.AsString()
+			if region != ExpectedRegion {
+				blockPath := strings.Join(path, "/")
+				if blockPath == "" {
+					blockPath = "<root>"
+				}
+				*violations = append(*violations, newViolation(attr.Range(), address, fmt.Sprintf("block %s sets region to %s (expected %s)", blockPath, region, ExpectedRegion), regionRemediation))
+			}
+		}
+	}
+
+	for _, block := range body.Blocks {
+		nestedAddress := address
+		if nestedAddress == "" {
+			nestedAddress = blockAddress(block)
+		}
+		nestedPath := append(path, block.Type)
+		nestedPath = append(nestedPath, block.Labels...)
+		walkBodyForRegions(block.Body, nestedAddress, nestedPath, violations)
+	}
+}
+
+func evaluateProviderAndBackendRegions(ws *Workspace) []Violation {
+	violations := requireBlocks(ws, ws.Providers("aws"), workspaceViolation("expected at least one aws provider block", "declare provider \"aws\" with region = \""+ExpectedRegion+"\""), ensureRegionAttribute)
+
+	backendChecked := false
+	for _, block := range ws.Blocks("terraform") {
+		for _, nested := range block.Body.Blocks {
+			if nested.Type != "backend" || len(nested.Labels) == 0 || nested.Labels[0] != "s3" {
+				continue
+			}
+			backendChecked = true
+			address := "terraform.backend.s3"
+			attr, ok := nested.Body.Attributes["region"]
+			if !ok {
+				violations = append(violations, newViolation(nested.DefRange(), address, "backend \"s3\" missing region", regionRemediation))
+				continue
+			}
+			val, diag := constValue(attr.Expr)
+			if diag.HasErrors() || val.Type() != cty.String || val.AsString() != ExpectedRegion {
+				violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("backend region must be %s", ExpectedRegion), regionRemediation))
+			}
+		}
+	}
+
+	if !backendChecked {
+		violations = append(violations, workspaceViolation("expected backend configuration to be checked", "declare a backend \"s3\" block with region = \""+ExpectedRegion+"\""))
+	}
+
+	return violations
+}
+
+func ensureRegionAttribute(block *Block) []Violation {
+	address := block.Address
+
+	attr, ok := block.Body.Attributes["region"]
+	if !ok {
+		return []Violation{newViolation(block.DefRange(), address, "aws provider missing region", regionRemediation)}
+	}
+
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("region must be a constant string (%s)", diag.Error()), regionRemediation)}
+	}
+
+	if val.Type() != cty.String {
+		return []Violation{newViolation(attr.Range(), address, "region must be a string literal", regionRemediation)}
+	}
+
+	if val.AsString() != ExpectedRegion {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("region set to %s (expected %s)", val.AsString(), ExpectedRegion), regionRemediation)}
+	}
+
+	return nil
+}
+
+func evaluateAvailabilityZones(ws *Workspace) []Violation {
+	tfvarsPath, attrs, err := ws.environmentAttributes("terraform.tfvars")
+	if err != nil {
+		return []Violation{errorViolation(err)}
+	}
+
+	const (
+		address     = "var.availability_zones"
+		remediation = "list only " + ExpectedRegion + " zones, for example [\"" + ExpectedRegion + "a\", \"" + ExpectedRegion + "b\"]"
+	)
+
+	attr, ok := attrs["availability_zones"]
+	if !ok {
+		return []Violation{fileViolation(tfvarsPath, "missing availability_zones", remediation)}
+	}
+
+	val, diag := constValue(attr.Expr)
+	if diag.HasErrors() {
+		return []Violation{newViolation(attr.Range(), address, fmt.Sprintf("availability_zones must be a constant list (%s)", diag.Error()), remediation)}
+	}
+	if !val.Type().IsListType() && !val.Type().IsTupleType() {
+		return []Violation{newViolation(attr.Range(), address, "availability_zones must be a list", remediation)}
+	}
+	if val.LengthInt() == 0 {
+		return []Violation{newViolation(attr.Range(), address, "availability_zones must not be empty", remediation)}
+	}
+
+	var violations []Violation
+	for i := 0; i < val.LengthInt(); i++ {
+		az, ok := knownString(val.Index(cty.NumberIntVal(int64(i))))
+		if !ok {
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("availability_zones[%d] must be string", i), remediation))
+			continue
+		}
+		if !strings.HasPrefix(az, ExpectedRegion) {
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("availability_zones[%d] must be in %s (got %s)", i, ExpectedRegion, az), remediation))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// toolName names the tool in machine-readable reports.
+const toolName = "berthcare-policy"
+
+// Report is the outcome of one engine run, handed to a Reporter.
+type Report struct {
+	// Root is the Terraform directory that was checked.
+	Root    string
+	Rules   []Rule
+	Results []Result
+	// Suppressions are the suppression comments honored during the run.
+	Suppressions []*Suppression
+	// Baseline is the baseline applied with ApplyBaseline, if any, and Fixed
+	// its entries that no longer match a violation.
+	Baseline *Baseline
+	Fixed    []BaselineEntry
+}
+
+// NewReport collects an engine's rules, the results of running them against
+// ws, and the suppressions that were honored.
+func NewReport(ws *Workspace, engine *Engine, results []Result) *Report {
+	return &Report{Root: ws.Root, Rules: engine.rulesFor(ws), Results: results, Suppressions: ws.ActiveSuppressions()}
+}
+
+// Violations returns every violation in the report, in result order.
+func (r *Report) Violations() []Violation {
+	var violations []Violation
+	for _, result := range r.Results {
+		violations = append(violations, result.Violations...)
+	}
+	return violations
+}
+
+// Failing returns the violations at least as serious as threshold, which
+// are the ones that fail a check.
+func (r *Report) Failing(threshold Severity) []Violation {
+	var failing []Violation
+	for _, v := range r.Violations() {
+		if v.Severity.AtLeast(threshold) {
+			failing = append(failing, v)
+		}
+	}
+	return failing
+}
+
+// Suppressed returns every violation a suppression accepted, in result
+// order.
+func (r *Report) Suppressed() []Violation {
+	var suppressed []Violation
+	for _, result := range r.Results {
+		suppressed = append(suppressed, result.Suppressed...)
+	}
+	return suppressed
+}
+
+// SuppressedBy returns the violations in the report that s accepted.
+func (r *Report) SuppressedBy(s *Suppression) []Violation {
+	var suppressed []Violation
+	for _, v := range r.Suppressed() {
+		if s.covers(v) {
+			suppressed = append(suppressed, v)
+		}
+	}
+	return suppressed
+}
+
+// suppressionFor returns the report's suppression accepting v.
+func (r *Report) suppressionFor(v Violation) *Suppression {
+	for _, s := range r.Suppressions {
+		if s.covers(v) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Environments returns the environments the report covers, sorted.
+func (r *Report) Environments() []string {
+	seen := map[string]bool{}
+	environments := []string{}
+	for _, result := range r.Results {
+		if result.Environment != "" && !seen[result.Environment] {
+			seen[result.Environment] = true
+			environments = append(environments, result.Environment)
+		}
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// Reporter writes a report in one output format.
+type Reporter interface {
+	Name() string
+	Write(w io.Writer, report *Report) error
+}
+
+var reporters = map[string]Reporter{}
+
+// RegisterReporter adds a reporter to the registry. It panics on a duplicate
+// name so that clashing reporters are caught at init time.
+func RegisterReporter(reporter Reporter) {
+	if _, exists := reporters[reporter.Name()]; exists {
+		panic(fmt.Sprintf("policy: reporter %s registered twice", reporter.Name()))
+	}
+	reporters[reporter.Name()] = reporter
+}
+
+// LookupReporter returns the registered reporter with the given name.
+func LookupReporter(name string) (Reporter, bool) {
+	reporter, ok := reporters[name]
+	return reporter, ok
+}
+
+// ReporterNames returns the name of every registered reporter, sorted.
+func ReporterNames() []string {
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reporterFunc adapts a write function to the Reporter interface.
+type reporterFunc struct {
+	name  string
+	write func(w io.Writer, report *Report) error
+}
+
+func (r *reporterFunc) Name() string { return r.name }
+
+func (r *reporterFunc) Write(w io.Writer, report *Report) error {
+	return r.write(w, report)
+}
+
+func init() {
+	RegisterReporter(&reporterFunc{name: "text", write: writeText})
+}
+
+func writeText(w io.Writer, report *Report) error {
+	violations := report.Violations()
+	for _, v := range violations {
+		if _, err := fmt.Fprintln(w, v.String()); err != nil {
+			return err
+		}
+		if v.Remediation != "" {
+			if _, err := fmt.Fprintf(w, "    remediation: %s\n", v.Remediation); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, s := range report.Suppressions {
+		_, err := fmt.Fprintf(w, "%s:%d: suppressed [%s] until %s: %s (%d finding(s))\n",
+			s.Range.Filename, s.Range.Start.Line, strings.Join(s.Rules, ","), s.Expires.Format(time.DateOnly), s.Reason, len(report.SuppressedBy(s)))
+		if err != nil {
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("%d violation(s), %d suppressed", len(violations), len(report.Suppressed()))
+	if report.Baseline != nil {
+		summary += fmt.Sprintf(", %d baselined, %d fixed since the baseline", len(report.Baselined()), len(report.Fixed))
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
@@ -0,0 +1,184 @@
+// Package policy evaluates BerthCare's infrastructure compliance properties
+// against a Terraform configuration. Each property is a registered Rule so the
+// same checks can run from go test, the CLI, or any other tool.
+package policy
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Severity ranks how serious a rule violation is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// severityRanks orders the severities from least to most serious.
+var severityRanks = map[Severity]int{SeverityInfo: 1, SeverityWarning: 2, SeverityError: 3}
+
+// ParseSeverity returns the severity with the given name.
+func ParseSeverity(name string) (Severity, error) {
+	severity := Severity(name)
+	if _, ok := severityRanks[severity]; !ok {
+		return "", fmt.Errorf("unknown severity %q (want error, warning or info)", name)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether s is as serious as threshold or more.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return severityRanks[s] >= severityRanks[threshold]
+}
+
+// Scope says what a rule needs to be evaluated against.
+type Scope string
+
+const (
+	// ScopeWorkspace rules check the configuration as written and give the
+	// same result for every environment.
+	ScopeWorkspace Scope = "workspace"
+	// ScopeEnvironment rules check the values one environment gives the
+	// configuration, so they run once per environment.
+	ScopeEnvironment Scope = "environment"
+)
+
+// Rule is a single compliance property evaluated against a workspace.
+type Rule interface {
+	ID() string
+	Description() string
+	Severity() Severity
+	Scope() Scope
+	Evaluate(ws *Workspace) []Violation
+}
+
+// planRule is implemented by rules that can also be evaluated against a
+// plan loaded with LoadPlan.
+type planRule interface {
+	SupportsPlan() bool
+}
+
+// planOnlyRule is implemented by rules that check what a plan changes, and
+// so have nothing to evaluate in a configuration.
+type planOnlyRule interface {
+	PlanOnly() bool
+}
+
+// SupportsPlan reports whether rule can be evaluated against a plan. Rules
+// that read files other than the Terraform configuration, such as an
+// environment's terraform.tfvars, cannot.
+func SupportsPlan(rule Rule) bool {
+	r, ok := rule.(planRule)
+	return ok && r.SupportsPlan()
+}
+
+// stateRule is implemented by rules that can also be evaluated against a
+// state file loaded with LoadState.
+type stateRule interface {
+	SupportsState() bool
+}
+
+// SupportsState reports whether rule can be evaluated against the attribute
+// values recorded in a state file. State records no provider configuration
+// or variables, so only rules that read resource attributes can.
+func SupportsState(rule Rule) bool {
+	r, ok := rule.(stateRule)
+	return ok && r.SupportsState()
+}
+
+// PlanOnly reports whether rule can only be evaluated against a plan.
+func PlanOnly(rule Rule) bool {
+	r, ok := rule.(planOnlyRule)
+	return ok && r.PlanOnly()
+}
+
+// environmentRule is implemented by environment-scoped rules that only hold
+// for some of the environments.
+type environmentRule interface {
+	AppliesTo(env string) bool
+}
+
+// AppliesTo reports whether rule is evaluated for the named environment.
+// Rules check every environment unless they say otherwise.
+func AppliesTo(rule Rule, env string) bool {
+	r, ok := rule.(environmentRule)
+	return !ok || r.AppliesTo(env)
+}
+
+var registry = map[string]Rule{}
+
+// Register adds a rule to the registry. It panics on a duplicate ID or an
+// unknown severity so that such rules are caught at init time.
+func Register(rule Rule) {
+	if _, exists := registry[rule.ID()]; exists {
+		panic(fmt.Sprintf("policy: rule %s registered twice", rule.ID()))
+	}
+	if _, err := ParseSeverity(string(rule.Severity())); err != nil {
+		panic(fmt.Sprintf("policy: rule %s: %v", rule.ID(), err))
+	}
+	registry[rule.ID()] = rule
+}
+
+// Lookup returns the registered rule with the given ID.
+func Lookup(id string) (Rule, bool) {
+	rule, ok := registry[id]
+	return rule, ok
+}
+
+// Rules returns every registered rule sorted by ID.
+func Rules() []Rule {
+	rules := make([]Rule, 0, len(registry))
+	for _, rule := range registry {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID() < rules[j].ID() })
+	return rules
+}
+
+// goRule adapts a validator function written in Go to the Rule interface.
+type goRule struct {
+	id          string
+	description string
+	severity    Severity
+	scope       Scope
+	// plan marks rules that also hold for planned values, and planOnly
+	// rules that check the plan's changes rather than any configuration.
+	// state marks rules that also hold for the values recorded in state.
+	plan     bool
+	planOnly bool
+	state    bool
+	// traces lists the properties the rule enforces.
+	traces   []Trace
+	evaluate func(ws *Workspace) []Violation
+}
+
+func (r *goRule) ID() string          { return r.id }
+func (r *goRule) Description() string { return r.description }
+func (r *goRule) Severity() Severity  { return r.severity }
+func (r *goRule) SupportsPlan() bool  { return r.plan || r.planOnly }
+func (r *goRule) PlanOnly() bool      { return r.planOnly }
+func (r *goRule) SupportsState() bool { return r.state }
+func (r *goRule) Traces() []Trace     { return r.traces }
+
+// Scope defaults to ScopeWorkspace when the rule does not set one.
+func (r *goRule) Scope() Scope {
+	if r.scope == "" {
+		return ScopeWorkspace
+	}
+	return r.scope
+}
+
+func (r *goRule) Evaluate(ws *Workspace) []Violation {
+	violations := r.evaluate(ws)
+	for i := range violations {
+		violations[i].RuleID = r.id
+		violations[i].Environment = ws.Environment
+		if violations[i].Severity == "" {
+			violations[i].Severity = r.severity
+		}
+	}
+	return violations
+}
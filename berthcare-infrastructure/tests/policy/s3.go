@@ -0,0 +1,242 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	Register(&goRule{
+		id:          "S3-001",
+		description: "S3 buckets must enable AES256 server-side encryption",
+		severity:    SeverityError,
+		traces:      []Trace{devS3.trace("3.1"), stagingS3.trace("3.1"), setupS3.trace()},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_s3_bucket"), checkBucketEncryption)
+		},
+	})
+
+	Register(&goRule{
+		id:          "S3-002",
+		description: "S3 buckets must enable versioning",
+		severity:    SeverityError,
+		traces:      []Trace{devS3.trace("3.2"), stagingS3.trace("3.2"), setupS3.trace()},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_s3_bucket"), checkBucketVersioning)
+		},
+	})
+
+	Register(&goRule{
+		id:          "S3-003",
+		description: "S3 public access blocks must enable all four protections",
+		severity:    SeverityError,
+		traces:      []Trace{devS3.trace("3.3"), stagingS3.trace("3.3"), setupS3.trace()},
+		plan:        true,
+		state:       true,
+		evaluate: func(ws *Workspace) []Violation {
+			return checkBlocks(ws, ws.Resources("aws_s3_bucket_public_access_block"), checkBucketPublicAccessBlock)
+		},
+	})
+
+	Register(&goRule{
+		id:          "S3-004",
+		description: "Photo and export bucket policies must grant access only to the ECS task role",
+		severity:    SeverityError,
+		traces:      []Trace{devS3.trace("3.4", "3.5"), stagingS3.trace("3.4", "3.5")},
+		scope:       ScopeEnvironment,
+		evaluate:    evaluateS3BucketPolicies,
+	})
+}
+
+var sseAlgorithmPath = []string{"server_side_encryption_configuration", "rule", "apply_server_side_encryption_by_default"}
+
+func checkBucketEncryption(block *Block) []Violation {
+	const remediation = "add server_side_encryption_configuration { rule { apply_server_side_encryption_by_default { sse_algorithm = \"AES256\" } } }"
+	address := block.Address
+	fix := setAttributeFix(block, sseAlgorithmPath, "sse_algorithm", cty.StringVal("AES256"))
+
+	for _, nested := range block.Body.Blocks {
+		if nested.Type != "server_side_encryption_configuration" {
+			continue
+		}
+
+		for _, rule := range nested.Body.Blocks {
+			if rule.Type != "rule" {
+				continue
+			}
+			for _, apply := range rule.Body.Blocks {
+				if apply.Type != "apply_server_side_encryption_by_default" {
+					continue
+				}
+				attr, ok := apply.Body.Attributes["sse_algorithm"]
+				if !ok {
+					return []Violation{withFix(newViolation(apply.DefRange(), address, "missing sse_algorithm in encryption block", "set sse_algorithm = \"AES256\""), fix)}
+				}
+
+				val, diag := constValue(attr.Expr)
+				if diag.HasErrors() || val.Type() != cty.String || val.AsString() != "AES256" {
+					return []Violation{withFix(newViolation(attr.Range(), address, "sse_algorithm must be AES256", "set sse_algorithm = \"AES256\""), literalFix(attr.Expr, fix))}
+				}
+				return nil
+			}
+		}
+	}
+
+	return []Violation{withFix(newViolation(block.DefRange(), address, "aws_s3_bucket missing server_side_encryption_configuration", remediation), fix)}
+}
+
+func checkBucketVersioning(block *Block) []Violation {
+	const remediation = "add versioning { enabled = true }"
+	address := block.Address
+	fix := setAttributeFix(block, []string{"versioning"}, "enabled", cty.True)
+
+	for _, nested := range block.Body.Blocks {
+		if nested.Type != "versioning" {
+			continue
+		}
+
+		attr, ok := nested.Body.Attributes["enabled"]
+		if !ok {
+			return []Violation{withFix(newViolation(nested.DefRange(), address, "versioning block missing enabled", remediation), fix)}
+		}
+
+		val, diag := constValue(attr.Expr)
+		if diag.HasErrors() || val.Type() != cty.Bool || !val.True() {
+			return []Violation{withFix(newViolation(attr.Range(), address, "versioning.enabled must be true", remediation), literalFix(attr.Expr, fix))}
+		}
+
+		return nil
+	}
+
+	return []Violation{withFix(newViolation(block.DefRange(), address, "aws_s3_bucket missing versioning block", remediation), fix)}
+}
+
+var publicAccessBlockAttributes = []string{
+	"block_public_acls",
+	"block_public_policy",
+	"ignore_public_acls",
+	"restrict_public_buckets",
+}
+
+func checkBucketPublicAccessBlock(block *Block) []Violation {
+	var violations []Violation
+	address := block.Address
+
+	for _, name := range publicAccessBlockAttributes {
+		remediation := fmt.Sprintf("set %s = true", name)
+		fix := setAttributeFix(block, nil, name, cty.True)
+
+		attr, ok := block.Body.Attributes[name]
+		if !ok {
+			violations = append(violations, withFix(newViolation(block.DefRange(), address, fmt.Sprintf("public access block missing %s", name), remediation), fix))
+			continue
+		}
+
+		val, diag := constValue(attr.Expr)
+		if diag.HasErrors() || val.Type() != cty.Bool || !val.True() {
+			violations = append(violations, withFix(newViolation(attr.Range(), address, fmt.Sprintf("%s must be true", name), remediation), literalFix(attr.Expr, fix)))
+		}
+	}
+
+	return violations
+}
+
+func evaluateS3BucketPolicies(ws *Workspace) []Violation {
+	taskRoleArn, violations := environmentString(ws, "task_role_arn")
+	if violations != nil {
+		return violations
+	}
+
+	var policies []*Block
+	for _, block := range ws.DataSources("aws_iam_policy_document") {
+		if block.Labels[1] == "photos" || block.Labels[1] == "exports" {
+			policies = append(policies, block)
+		}
+	}
+
+	found := false
+	for _, block := range policies {
+		violations = append(violations, checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+			policyViolations, policyFound := checkBucketPolicyPrincipals(block, ev, address, taskRoleArn)
+			found = found || policyFound
+			return policyViolations
+		})...)
+	}
+
+	if !found {
+		violations = append(violations, workspaceViolation("expected at least one S3 bucket policy to validate", "declare aws_iam_policy_document photos and exports for the bucket policies"))
+	}
+
+	return violations
+}
+
+func checkBucketPolicyPrincipals(block *Block, ev *Evaluator, address string, expectedRole string) ([]Violation, bool) {
+	const remediation = "grant access only to [var.task_role_arn] with principals type \"AWS\""
+
+	var violations []Violation
+	found := false
+
+	for _, stmt := range block.Body.Blocks {
+		if stmt.Type != "statement" {
+			continue
+		}
+
+		for _, principal := range stmt.Body.Blocks {
+			if principal.Type != "principals" {
+				continue
+			}
+
+			found = true
+
+			typeAttr, ok := principal.Body.Attributes["type"]
+			if !ok || !isConstString(typeAttr, "AWS") {
+				violations = append(violations, newViolation(principal.DefRange(), address, "principals.type must be \"AWS\"", remediation))
+				continue
+			}
+
+			identifiersAttr, ok := principal.Body.Attributes["identifiers"]
+			if !ok {
+				violations = append(violations, newViolation(principal.DefRange(), address, "principals missing identifiers", remediation))
+				continue
+			}
+
+			val, identDiag := ev.Value(identifiersAttr.Expr)
+			if identDiag.HasErrors() {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, fmt.Sprintf("identifiers must be a resolvable list (%s)", identDiag.Error()), remediation))
+				continue
+			}
+
+			if !val.IsWhollyKnown() || val.IsNull() {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, "identifiers must be known before apply", remediation))
+				continue
+			}
+
+			if !val.Type().IsListType() && !val.Type().IsTupleType() {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, "identifiers must be a list", remediation))
+				continue
+			}
+
+			if val.LengthInt() != 1 {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, "identifiers must contain only the ECS task role", remediation))
+				continue
+			}
+
+			elem, ok := knownString(val.Index(cty.NumberIntVal(0)))
+			if !ok {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, "identifiers[0] must be string", remediation))
+				continue
+			}
+
+			if elem != expectedRole {
+				violations = append(violations, newViolation(identifiersAttr.Range(), address, fmt.Sprintf("identifiers[0] must equal task_role_arn (%s)", expectedRole), remediation))
+			}
+		}
+	}
+
+	return violations, found
+}
@@ -0,0 +1,227 @@
+package policy
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// The sarif types cover the subset of SARIF 2.1.0 that GitHub code scanning
+// reads.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID        string             `json:"ruleId"`
+	RuleIndex     int                `json:"ruleIndex"`
+	Level         string             `json:"level"`
+	Message       sarifMessage       `json:"message"`
+	Locations     []sarifLocation    `json:"locations"`
+	Suppressions  []sarifSuppression `json:"suppressions,omitempty"`
+	BaselineState string             `json:"baselineState,omitempty"`
+	Properties    map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func init() {
+	RegisterReporter(&reporterFunc{name: "sarif", write: writeSARIF})
+}
+
+// writeSARIF writes the report as a SARIF 2.1.0 log. Relative file paths are
+// emitted relative to %SRCROOT%, so run the check from the repository root
+// for GitHub to match them to files. Findings not tied to a file are located
+// at the Terraform directory that was checked.
+func writeSARIF(w io.Writer, report *Report) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: make([]sarifRule, 0, len(report.Rules))}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	addRule := func(rule Rule) {
+		ruleIndex[rule.ID()] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.ID(),
+			ShortDescription:     sarifMessage{Text: rule.Description()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity())},
+			Properties:           map[string]string{"scope": string(rule.Scope())},
+		})
+	}
+	for _, rule := range report.Rules {
+		addRule(rule)
+	}
+	// A result's ruleIndex must point at its own rule, so rules the report
+	// was not given, such as the syntax rule, are added when first seen.
+	indexOf := func(v Violation) int {
+		if index, ok := ruleIndex[v.RuleID]; ok {
+			return index
+		}
+		rule, ok := Lookup(v.RuleID)
+		if !ok {
+			rule = &goRule{id: v.RuleID, severity: v.Severity}
+		}
+		addRule(rule)
+		return ruleIndex[v.RuleID]
+	}
+
+	for _, v := range report.Violations() {
+		result := sarifViolation(report.Root, indexOf(v), v)
+		if report.Baseline != nil {
+			result.BaselineState = "new"
+		}
+		run.Results = append(run.Results, result)
+	}
+	for _, v := range report.Baselined() {
+		result := sarifViolation(report.Root, indexOf(v), v)
+		result.BaselineState = "unchanged"
+		run.Results = append(run.Results, result)
+	}
+
+	// Suppressed findings are reported as suppressed in source so that code
+	// scanning shows them as dismissed rather than dropping them.
+	for _, v := range report.Suppressed() {
+		result := sarifViolation(report.Root, indexOf(v), v)
+		if s := report.suppressionFor(v); s != nil {
+			result.Suppressions = []sarifSuppression{{Kind: "inSource", Justification: s.Reason}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	log := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifViolation(root string, index int, v Violation) sarifResult {
+	text := v.Message
+	if v.Environment != "" {
+		text = v.Environment + ": " + text
+	}
+	if v.Remediation != "" {
+		text += ". Remediation: " + v.Remediation
+	}
+
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact(root + "/")}}
+	if v.Range.Filename != "" {
+		location.PhysicalLocation.ArtifactLocation = sarifArtifact(v.Range.Filename)
+	}
+	if v.Range.Start.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   v.Range.Start.Line,
+			StartColumn: v.Range.Start.Column,
+			EndLine:     v.Range.End.Line,
+			EndColumn:   v.Range.End.Column,
+		}
+	}
+	if v.Resource != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: v.Resource, Kind: "resource"}}
+	}
+
+	result := sarifResult{
+		RuleID:    v.RuleID,
+		RuleIndex: index,
+		Level:     sarifLevel(v.Severity),
+		Message:   sarifMessage{Text: text},
+		Locations: []sarifLocation{location},
+	}
+	if v.Environment != "" {
+		result.Properties = map[string]string{"environment": v.Environment}
+	}
+	return result
+}
+
+func sarifArtifact(path string) sarifArtifactLocation {
+	if filepath.IsAbs(path) {
+		return sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()}
+	}
+
+	trailing := strings.HasSuffix(path, "/")
+	path = filepath.ToSlash(filepath.Clean(path))
+	if trailing && path != "." {
+		path += "/"
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Path: path}).String(), URIBaseID: "%SRCROOT%"}
+}
+
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,508 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ChangeSet is what changed in a workspace since a git revision, and what
+// those changes can affect.
+type ChangeSet struct {
+	// Ref is the revision compared against, as given.
+	Ref string
+	// Files lists the changed Terraform files and environment files under
+	// the workspace root, committed or not, including untracked ones, as the
+	// workspace names its files. Files deleted since Ref are left out.
+	Files []string
+	// Deleted lists the Terraform files and environment files under the
+	// workspace root deleted since Ref, named as Files are.
+	Deleted []string
+	// Affected lists, sorted, the qualified address of every block declared
+	// in a changed file and of every block that depends on one, such as
+	// module.rds.aws_db_instance.this. A block depends on another when it
+	// references it, directly or through variables, locals, module
+	// arguments and module outputs. RunSinceContext adds the blocks that
+	// changed and deleted files declared at Ref, and their dependents there.
+	Affected []string
+
+	// modified lists the files in Files that existed at Ref.
+	modified []string
+}
+
+// Changes asks git which files under the workspace root differ from ref and
+// works out the blocks those changes affect. A renamed file counts as the
+// deletion of its old path and the addition of its new one.
+func Changes(ws *Workspace, ref string) (*ChangeSet, error) {
+	if _, err := git(ws.Root, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%s is not a commit in the git repository at %s", ref, ws.Root)
+	}
+
+	changed, err := git(ws.Root, "diff", "--name-status", "--no-renames", "--relative", "-z", ref, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(ws.Root, "ls-files", "--others", "--exclude-standard", "-z", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	// The diff alternates a status letter and a path; untracked files are
+	// added ones.
+	var statuses, names []string
+	fields := strings.Split(strings.TrimSuffix(string(changed), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		statuses = append(statuses, fields[i])
+		names = append(names, fields[i+1])
+	}
+	for _, name := range strings.Split(string(untracked), "\x00") {
+		if name != "" {
+			statuses = append(statuses, "A")
+			names = append(names, name)
+		}
+	}
+
+	changes := &ChangeSet{Ref: ref}
+	for i, name := range names {
+		path := filepath.Join(ws.Root, filepath.FromSlash(name))
+		if statuses[i] == "D" {
+			if (filepath.Ext(path) == ".tf" || isEnvironmentFile(path)) && !slices.Contains(changes.Deleted, path) {
+				changes.Deleted = append(changes.Deleted, path)
+			}
+			continue
+		}
+
+		relevant := slices.ContainsFunc(ws.Files, func(f *File) bool { return f.Path == path })
+		if !relevant && isEnvironmentFile(path) {
+			_, err := os.Stat(path)
+			relevant = err == nil
+		}
+		if relevant && !slices.Contains(changes.Files, path) {
+			changes.Files = append(changes.Files, path)
+			if statuses[i] != "A" {
+				changes.modified = append(changes.modified, path)
+			}
+		}
+	}
+	slices.Sort(changes.Files)
+	slices.Sort(changes.Deleted)
+
+	changes.Affected = ws.affectedBy(changes.Files)
+	return changes, nil
+}
+
+// affectedBy returns the qualified addresses of the blocks declared in files
+// and of everything that depends on them. A changed environment file affects
+// every root variable, since it may set any of them.
+func (ws *Workspace) affectedBy(files []string) []string {
+	dependents := ws.dependencyGraph()
+
+	var queue []string
+	for _, file := range files {
+		if isEnvironmentFile(file) {
+			for _, block := range ws.Blocks("variable") {
+				if block.Module == "." {
+					queue = append(queue, block.Address)
+				}
+			}
+			continue
+		}
+		for _, block := range ws.fileBlocks(file) {
+			for _, inst := range ws.Instances(block.Module) {
+				queue = append(queue, blockNodes(inst, block)...)
+			}
+		}
+	}
+
+	affected := map[string]bool{}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if affected[node] {
+			continue
+		}
+		affected[node] = true
+		queue = append(queue, dependents[node]...)
+	}
+	return sortedKeys(affected)
+}
+
+// isEnvironmentFile reports whether path is a file of one of the
+// environments, such as environments/dev/terraform.tfvars.
+func isEnvironmentFile(path string) bool {
+	return filepath.Base(filepath.Dir(filepath.Dir(path))) == "environments"
+}
+
+// fileBlocks returns the top-level blocks declared in the named file.
+func (ws *Workspace) fileBlocks(path string) []*Block {
+	var blocks []*Block
+	for _, kind := range sortedKeys(ws.index.byKind) {
+		for _, block := range ws.index.byKind[kind] {
+			if block.File.Path == path {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	return blocks
+}
+
+// blockNodes returns the graph nodes a block stands for in inst: its
+// qualified address, or one local.<name> for each value of a locals block.
+func blockNodes(inst *ModuleInstance, block *Block) []string {
+	if block.Type != "locals" {
+		return []string{inst.qualify(block.Address)}
+	}
+	var nodes []string
+	for _, name := range sortedKeys(block.Body.Attributes) {
+		nodes = append(nodes, inst.qualify("local."+name))
+	}
+	return nodes
+}
+
+// dependencyGraph maps the qualified address of every block in every module
+// instance to the blocks that depend on it.
+func (ws *Workspace) dependencyGraph() map[string][]string {
+	dependents := map[string][]string{}
+	edge := func(from string, to string) {
+		if from != to && !slices.Contains(dependents[from], to) {
+			dependents[from] = append(dependents[from], to)
+		}
+	}
+
+	for _, kind := range sortedKeys(ws.index.byKind) {
+		for _, block := range ws.index.byKind[kind] {
+			for _, inst := range ws.Instances(block.Module) {
+				if block.Type == "locals" {
+					for name, attr := range block.Body.Attributes {
+						for _, dep := range references(inst, attr.Expr.Variables()) {
+							edge(dep, inst.qualify("local."+name))
+						}
+					}
+					continue
+				}
+
+				node := inst.qualify(block.Address)
+				for _, dep := range references(inst, bodyVariables(block.Body)) {
+					edge(dep, node)
+				}
+
+				// A module call's arguments are the values of the called
+				// module's variables.
+				if block.Type == "module" && len(block.Labels) == 1 {
+					if child := inst.Child(block.Labels[0]); child != nil {
+						for _, variable := range ws.Blocks("variable") {
+							if variable.Module == child.Dir {
+								edge(node, child.qualify(variable.Address))
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return dependents
+}
+
+// references resolves the traversals of expressions evaluated in inst to the
+// qualified addresses of the blocks they read. A module output resolves to
+// the output block in the called module.
+func references(inst *ModuleInstance, traversals []hcl.Traversal) []string {
+	var addresses []string
+	for _, trav := range traversals {
+		names := traversalNames(trav)
+		if len(names) < 2 {
+			continue
+		}
+
+		switch names[0] {
+		case "each", "count", "path", "self", "terraform":
+		case "module":
+			child := inst.Child(names[1])
+			switch {
+			case child == nil:
+			case len(names) >= 3:
+				addresses = append(addresses, child.qualify("output."+names[2]))
+			default:
+				addresses = append(addresses, inst.qualify("module."+names[1]))
+			}
+		case "data":
+			if len(names) >= 3 {
+				addresses = append(addresses, inst.qualify(strings.Join(names[:3], ".")))
+			}
+		default:
+			addresses = append(addresses, inst.qualify(names[0]+"."+names[1]))
+		}
+	}
+	return addresses
+}
+
+// bodyVariables returns the traversals of every expression in body and in
+// the blocks nested in it.
+func bodyVariables(body *hclsyntax.Body) []hcl.Traversal {
+	var traversals []hcl.Traversal
+	for _, attr := range body.Attributes {
+		traversals = append(traversals, attr.Expr.Variables()...)
+	}
+	for _, nested := range body.Blocks {
+		traversals = append(traversals, bodyVariables(nested.Body)...)
+	}
+	return traversals
+}
+
+// RunSinceContext is RunEnvironmentsContext narrowed to what changed since
+// the git revision ref. Rules check only the affected blocks, though they
+// still read any other block they need. Their violations are kept if they
+// are about an affected block or in a changed file. Any other violation,
+// such as one a workspace-level rule reports, is kept only if checking the
+// same blocks of the workspace as it was at ref does not also report it.
+//
+// When no file changed or was deleted, no rule is evaluated. The workspace
+// as it was at ref is loaded only when a file that existed there changed or
+// was deleted, or when some violation is left to compare. Only the rules and
+// environments with such violations are evaluated against it.
+func (e *Engine) RunSinceContext(ctx context.Context, ws *Workspace, environments []string, ref string) ([]Result, *ChangeSet, error) {
+	changes, err := Changes(ws, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(changes.Files) == 0 && len(changes.Deleted) == 0 {
+		jobs := e.environmentJobs(ws, environments)
+		results := make([]Result, len(jobs))
+		for i, j := range jobs {
+			results[i] = Result{Rule: j.rule, Environment: j.ws.Environment}
+		}
+		return results, changes, nil
+	}
+
+	dir, err := os.MkdirTemp("", "berthcare-policy-since-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+	var base *Workspace
+	loadBase := func() error {
+		if base != nil {
+			return nil
+		}
+		if err := checkout(ws.Root, ref, dir); err != nil {
+			return err
+		}
+		loaded, err := LoadWorkspace(dir)
+		if err != nil {
+			return err
+		}
+		base = loaded
+		return nil
+	}
+
+	// What changed and deleted files declared at ref may be gone now, and
+	// what depended on it then is affected as well.
+	if len(changes.modified) > 0 || len(changes.Deleted) > 0 {
+		if err := loadBase(); err != nil {
+			return nil, nil, err
+		}
+		var files []string
+		for _, file := range slices.Concat(changes.modified, changes.Deleted) {
+			rel, err := filepath.Rel(ws.Root, file)
+			if err != nil {
+				return nil, nil, err
+			}
+			files = append(files, filepath.Join(base.Root, rel))
+		}
+		changes.Affected = slices.Concat(changes.Affected, base.affectedBy(files))
+		slices.Sort(changes.Affected)
+		changes.Affected = slices.Compact(changes.Affected)
+	}
+
+	results, err := e.runJobs(ctx, e.environmentJobs(ws.focusOn(changes.Affected), environments))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Violations the changes touch are kept as they are; the rest are kept
+	// only if the workspace did not have them at ref.
+	var compare []Rule
+	var compareEnvironments []string
+	for _, result := range results {
+		for _, v := range slices.Concat(result.Violations, result.Suppressed) {
+			if changes.touches(ws, v) {
+				continue
+			}
+			if !slices.Contains(compare, result.Rule) {
+				compare = append(compare, result.Rule)
+			}
+			if result.Environment != "" && !slices.Contains(compareEnvironments, result.Environment) {
+				compareEnvironments = append(compareEnvironments, result.Environment)
+			}
+		}
+	}
+	if len(compare) == 0 {
+		return results, changes, nil
+	}
+
+	if err := loadBase(); err != nil {
+		return nil, nil, err
+	}
+	before, err := (&Engine{Workers: e.Workers, RuleTimeout: e.RuleTimeout, rules: compare}).violationsIn(ctx, base.focusOn(changes.Affected), compareEnvironments, ws.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keep := func(violations []Violation) []Violation {
+		var kept []Violation
+		for _, v := range violations {
+			fingerprint := Fingerprint(ws.Root, v)
+			switch {
+			case changes.touches(ws, v):
+				kept = append(kept, v)
+			case before[fingerprint] > 0:
+				before[fingerprint]--
+			default:
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	}
+	for i := range results {
+		results[i].Violations = keep(results[i].Violations)
+		results[i].Suppressed = keep(results[i].Suppressed)
+	}
+	return results, changes, nil
+}
+
+// touches reports whether v is about an affected block or a changed file.
+func (c *ChangeSet) touches(ws *Workspace, v Violation) bool {
+	if slices.Contains(c.Files, v.Range.Filename) {
+		return true
+	}
+	if v.Resource == "" {
+		return false
+	}
+	if _, ok := slices.BinarySearch(c.Affected, v.Resource); ok {
+		return true
+	}
+
+	// Some rules name a block by its address within its module; the file
+	// tells which module that is.
+	if v.Range.Filename == "" {
+		return false
+	}
+	module := "."
+	if rel, err := filepath.Rel(ws.Root, filepath.Dir(v.Range.Filename)); err == nil {
+		module = filepath.ToSlash(rel)
+	}
+	for _, inst := range ws.Instances(module) {
+		if _, ok := slices.BinarySearch(c.Affected, inst.qualify(v.Resource)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// violationsIn evaluates the engine against base, a checkout of the
+// workspace at root, and counts its violations by fingerprint as if they
+// were reported under root. Environments base does not have are skipped.
+func (e *Engine) violationsIn(ctx context.Context, base *Workspace, environments []string, root string) (map[string]int, error) {
+	existing, err := base.Environments()
+	if err != nil {
+		return nil, err
+	}
+	environments = slices.DeleteFunc(slices.Clone(environments), func(env string) bool {
+		return !slices.Contains(existing, env)
+	})
+
+	results, err := e.RunEnvironmentsContext(ctx, base, environments)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, result := range results {
+		for _, v := range slices.Concat(result.Violations, result.Suppressed) {
+			// Messages quoting a diagnostic name the file it is in; name it
+			// as the workspace does.
+			v.Message = strings.ReplaceAll(v.Message, base.Root, root)
+			counts[Fingerprint(base.Root, v)]++
+		}
+	}
+	return counts, nil
+}
+
+// checkout writes the tree root had at ref into dir. A root that did not
+// exist at ref leaves dir empty.
+func checkout(root string, ref string, dir string) error {
+	prefix, err := git(root, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	tree := ref + ":" + strings.TrimSuffix(strings.TrimSpace(string(prefix)), "/")
+	if _, err := git(root, "cat-file", "-e", tree); err != nil {
+		return nil
+	}
+
+	// Run from a subdirectory, git archive would also filter the tree by
+	// that subdirectory's path.
+	top, err := git(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	archive, err := git(strings.TrimSpace(string(top)), "archive", "--format=tar", tree)
+	if err != nil {
+		return err
+	}
+
+	r := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("git archive of %s holds %s, outside the tree", tree, header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			var content []byte
+			if content, err = io.ReadAll(r); err == nil {
+				if err = os.MkdirAll(filepath.Dir(target), 0o755); err == nil {
+					err = os.WriteFile(target, content, 0o644)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// git runs a git command in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,277 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The state types cover the parts of Terraform's version 4 state format that
+// LoadState reads.
+type stateJSON struct {
+	Version   int             `json:"version"`
+	Resources []stateResource `json:"resources"`
+}
+
+type stateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Instances []stateInstance `json:"instances"`
+}
+
+type stateInstance struct {
+	IndexKey   any            `json:"index_key"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// StateResource is one resource recorded in a state file.
+type StateResource struct {
+	// Address is the resource's address without instance keys, such as
+	// module.s3.aws_s3_bucket.photos.
+	Address string
+	// Module is the address of the module instance holding the resource,
+	// empty for the root module.
+	Module string
+	Mode   string
+	Type   string
+	Name   string
+	// Instances holds the address of every instance of the resource, such
+	// as aws_s3_bucket.scratch["a"].
+	Instances []string
+}
+
+// LoadState reads a local state file in Terraform's version 4 format into a
+// workspace that rules evaluate like a configuration.
+//
+// Every resource instance becomes a resource block in its module whose
+// attributes are the values recorded in state, rendered as LoadPlan renders
+// planned values. State records no variables or provider configuration, so
+// only rules that support state are evaluated against the workspace, and
+// their findings are located at the state file, identified by the instance's
+// address.
+func LoadState(path string) (*Workspace, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state stateJSON
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("%s: unsupported state version %d", path, state.Version)
+	}
+
+	ws := &Workspace{
+		Root: filepath.Dir(path),
+		index: &index{
+			byKind:          map[string][]*Block{},
+			byAddress:       map[string][]*Block{},
+			attributes:      map[string]attributeFile{},
+			evaluations:     map[string]*evaluation{},
+			instances:       map[string][]*ModuleInstance{},
+			state:           path,
+			renderedModules: map[string]string{},
+		},
+	}
+
+	resources := map[string][]planResource{"": nil}
+	for _, resource := range state.Resources {
+		local := resource.Type + "." + resource.Name
+		if resource.Mode == "data" {
+			local = "data." + local
+		}
+		inventory := StateResource{
+			Address: qualifyAddress(stripInstanceKeys(resource.Module), local),
+			Module:  resource.Module,
+			Mode:    resource.Mode,
+			Type:    resource.Type,
+			Name:    resource.Name,
+		}
+
+		for _, instance := range resource.Instances {
+			address := qualifyAddress(resource.Module, local) + instanceKey(instance.IndexKey)
+			inventory.Instances = append(inventory.Instances, address)
+			resources[resource.Module] = append(resources[resource.Module], planResource{
+				Address: address,
+				Mode:    resource.Mode,
+				Type:    resource.Type,
+				Name:    resource.Name,
+				Index:   instance.IndexKey,
+				Values:  instance.Attributes,
+			})
+		}
+		ws.index.stateResources = append(ws.index.stateResources, inventory)
+	}
+	root := stateModule("", resources)
+
+	r := &planRenderer{}
+	ws.index.root = &ModuleInstance{Dir: "."}
+	if err := ws.addRenderedModule(ws.index.root, r.renderModule(root, nil)); err != nil {
+		return nil, err
+	}
+	if err := ws.addPlanChildren(ws.index.root, root.ChildModules, r, nil); err != nil {
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// stateModule builds the module with the given address, and its
+// descendants, from the resources recorded for each module address. Modules
+// that hold no resources themselves but have descendants that do are
+// included, and children are ordered by address.
+func stateModule(address string, resources map[string][]planResource) planModule {
+	module := planModule{Address: address, Resources: resources[address]}
+
+	children := map[string]bool{}
+	for descendant := range resources {
+		if child, ok := childModuleAddress(address, descendant); ok {
+			children[child] = true
+		}
+	}
+	for _, child := range sortedKeys(children) {
+		module.ChildModules = append(module.ChildModules, stateModule(child, resources))
+	}
+	return module
+}
+
+// childModuleAddress returns the address of parent's child module that
+// descendant is, or is nested in.
+func childModuleAddress(parent string, descendant string) (string, bool) {
+	rest := descendant
+	if parent != "" {
+		if !strings.HasPrefix(descendant, parent+".") {
+			return "", false
+		}
+		rest = strings.TrimPrefix(descendant, parent+".")
+	}
+	if !strings.HasPrefix(rest, "module.") {
+		return "", false
+	}
+	if i := strings.Index(rest, ".module."); i >= 0 {
+		rest = rest[:i]
+	}
+	return qualifyAddress(parent, rest), true
+}
+
+// StateFile returns the state file the workspace was loaded from, or "" for
+// a workspace loaded from configuration or a plan.
+func (ws *Workspace) StateFile() string {
+	return ws.index.state
+}
+
+// StateResources returns the inventory of resources recorded in the state
+// file, in state order. A workspace not loaded from state has none.
+func (ws *Workspace) StateResources() []StateResource {
+	return ws.index.stateResources
+}
+
+// Drift is how a state file's inventory differs from the managed resources
+// a configuration declares, compared by address without instance keys.
+type Drift struct {
+	// OnlyInState holds resources recorded in state that the configuration
+	// no longer declares: destroying them is the next apply's job, or they
+	// were removed from code without being removed from state.
+	OnlyInState []string
+	// OnlyInCode holds resources the configuration declares that have no
+	// state entry: they have not been applied yet, or were created outside
+	// Terraform and never imported.
+	OnlyInCode []string
+}
+
+// CompareState compares the managed resources in state with those config
+// declares. Resources in modules the configuration calls but whose source is
+// not a local directory cannot be checked and are left out.
+func CompareState(config *Workspace, state *Workspace) Drift {
+	declared := map[string]bool{}
+	var unfollowed []string
+	var walk func(inst *ModuleInstance)
+	walk = func(inst *ModuleInstance) {
+		for _, block := range config.Blocks("resource") {
+			if block.Module == inst.Dir {
+				declared[inst.qualify(block.Address)] = true
+			}
+		}
+		for _, call := range config.Blocks("module") {
+			if call.Module == inst.Dir && len(call.Labels) == 1 && inst.Child(call.Labels[0]) == nil {
+				unfollowed = append(unfollowed, inst.qualify("module."+call.Labels[0]))
+			}
+		}
+		for _, child := range inst.Children {
+			walk(child)
+		}
+	}
+	walk(config.RootModule())
+
+	var drift Drift
+	recorded := map[string]bool{}
+	for _, resource := range state.StateResources() {
+		if resource.Mode != "managed" {
+			continue
+		}
+		if inModules(stripInstanceKeys(resource.Module), unfollowed) {
+			continue
+		}
+		if !declared[resource.Address] && !recorded[resource.Address] {
+			drift.OnlyInState = append(drift.OnlyInState, resource.Address)
+		}
+		recorded[resource.Address] = true
+	}
+	for address := range declared {
+		if !recorded[address] {
+			drift.OnlyInCode = append(drift.OnlyInCode, address)
+		}
+	}
+
+	sort.Strings(drift.OnlyInState)
+	sort.Strings(drift.OnlyInCode)
+	return drift
+}
+
+// inModules reports whether module is one of modules or nested in one.
+func inModules(module string, modules []string) bool {
+	for _, m := range modules {
+		if module == m || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceKeyPattern matches the instance key of a module or resource
+// instance address, such as ["photos"] or [0].
+var instanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+func stripInstanceKeys(address string) string {
+	return instanceKeyPattern.ReplaceAllString(address, "")
+}
+
+func qualifyAddress(module string, address string) string {
+	if module == "" {
+		return address
+	}
+	return module + "." + address
+}
+
+// instanceKey renders a state instance's index_key as it appears in an
+// address.
+func instanceKey(key any) string {
+	switch key := key.(type) {
+	case string:
+		return fmt.Sprintf("[%q]", key)
+	case json.Number:
+		return "[" + key.String() + "]"
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// subnetPlacement describes an attribute that must only ever receive subnets
+// from one side of the public/private split.
+type subnetPlacement struct {
+	noun      string
+	attribute string
+	// variable is the module input the attribute is expected to read. It is
+	// only relied on when the module is not called from the root module, so
+	// there is no data flow to follow.
+	variable string
+	public   bool
+}
+
+func (p subnetPlacement) kind() string {
+	if p.public {
+		return "public"
+	}
+	return "private"
+}
+
+func (p subnetPlacement) remediation() string {
+	return fmt.Sprintf("set %s = var.%s and wire it to module.vpc.%s", p.attribute, p.variable, p.variable)
+}
+
+// checkSubnetPlacement follows the attribute through every instance of the
+// block's module back to the aws_subnet resources it is built from and
+// reports subnets on the wrong side of the split.
+func checkSubnetPlacement(ws *Workspace, block *Block, placement subnetPlacement) []Violation {
+	remediation := placement.remediation()
+
+	attr, ok := block.Body.Attributes[placement.attribute]
+	if !ok {
+		return []Violation{newViolation(block.DefRange(), block.Address, fmt.Sprintf("%s missing %s", placement.noun, placement.attribute), remediation)}
+	}
+
+	if val, diag := constValue(attr.Expr); !diag.HasErrors() {
+		if !val.Type().IsListType() && !val.Type().IsTupleType() {
+			return []Violation{newViolation(attr.Range(), block.Address, fmt.Sprintf("%s must be a list of subnet IDs", placement.attribute), remediation)}
+		}
+
+		var violations []Violation
+		for i := 0; i < val.LengthInt(); i++ {
+			if _, ok := knownString(val.Index(cty.NumberIntVal(int64(i)))); !ok {
+				violations = append(violations, newViolation(attr.Range(), block.Address, fmt.Sprintf("subnet id at index %d must be string", i), remediation))
+			}
+		}
+		return violations
+	}
+
+	var violations []Violation
+	for _, inst := range ws.Instances(block.Module) {
+		address := inst.qualify(block.Address)
+		subnets, origins := ws.tracedResources(inst, attr.Expr, "aws_subnet")
+
+		for _, subnet := range subnets {
+			if subnetIsPublic(subnet.Block) != placement.public {
+				violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("%s traces to %s, which is not a %s subnet", placement.attribute, subnet, placement.kind()), remediation))
+			}
+		}
+		if len(subnets) > 0 {
+			continue
+		}
+
+		others, unbound := otherOrigins(origins)
+		switch {
+		case len(others) > 0:
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("%s traces to %s instead of %s subnets", placement.attribute, strings.Join(others, ", "), placement.kind()), remediation))
+		case unbound && !isVarReference(attr.Expr, placement.variable):
+			violations = append(violations, newViolation(attr.Range(), address, fmt.Sprintf("%s must be a constant list or %s", placement.attribute, placement.variable), remediation))
+		}
+	}
+
+	return violations
+}
+
+// subnetIsPublic reports whether instances launched in the subnet receive a
+// public IP, which is how the VPC module marks its public tier.
+func subnetIsPublic(subnet *Block) bool {
+	attr, ok := subnet.Body.Attributes["map_public_ip_on_launch"]
+	if !ok {
+		return false
+	}
+	val, diag := constValue(attr.Expr)
+	return !diag.HasErrors() && val.Type() == cty.Bool && val.True()
+}
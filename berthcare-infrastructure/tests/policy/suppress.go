@@ -0,0 +1,263 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// suppressionMarker starts an inline suppression comment, such as
+//
+//	# berthcare-policy:ignore RDS-004 reason="dev only" expires=2027-01-31
+//
+// placed at the end of the line a block or attribute starts on, or on the
+// line above it. Several rule IDs may be given separated by commas.
+const suppressionMarker = "berthcare-policy:ignore"
+
+// suppressionRuleID reports suppression comments that cannot be honored.
+// Like the syntax rule, NewEngine always selects it so that a suppression
+// cannot hide a finding without being valid itself.
+const suppressionRuleID = "POLICY-001"
+
+// today returns the date suppression expiry is checked against.
+var today = func() time.Time { return time.Now() }
+
+// Suppression is an inline comment accepting the named rules' findings in
+// the block or attribute it annotates.
+type Suppression struct {
+	Rules  []string
+	Reason string
+	// Expires is the last day the suppression is honored, zero when the
+	// comment gives none.
+	Expires time.Time
+	// Range is the comment itself.
+	Range hcl.Range
+	// Target is the block or attribute the comment annotates, with a zero
+	// Filename when it annotates nothing.
+	Target hcl.Range
+
+	syntaxErr string
+}
+
+// Problems returns why the suppression cannot be honored on the given day;
+// it is active when there are none.
+func (s *Suppression) Problems(on time.Time) []string {
+	if s.syntaxErr != "" {
+		return []string{s.syntaxErr}
+	}
+
+	var problems []string
+	for _, id := range s.Rules {
+		if _, ok := Lookup(id); !ok {
+			problems = append(problems, fmt.Sprintf("unknown rule %s", id))
+		}
+	}
+	if strings.TrimSpace(s.Reason) == "" {
+		problems = append(problems, "missing reason")
+	}
+	switch {
+	case s.Expires.IsZero():
+		problems = append(problems, "missing expires date")
+	case on.Format(time.DateOnly) > s.Expires.Format(time.DateOnly):
+		problems = append(problems, fmt.Sprintf("expired on %s", s.Expires.Format(time.DateOnly)))
+	}
+	if s.Target.Filename == "" {
+		problems = append(problems, "does not annotate a block or attribute")
+	}
+	return problems
+}
+
+// covers reports whether the suppression accepts v.
+func (s *Suppression) covers(v Violation) bool {
+	if !slices.Contains(s.Rules, v.RuleID) || v.Range.Filename != s.Target.Filename || v.Range.Start.Line == 0 {
+		return false
+	}
+	return s.Target.ContainsOffset(v.Range.Start.Byte)
+}
+
+// Suppressions returns every suppression comment in the workspace.
+func (ws *Workspace) Suppressions() []*Suppression {
+	return ws.index.suppressions
+}
+
+// ActiveSuppressions returns the suppressions honored today.
+func (ws *Workspace) ActiveSuppressions() []*Suppression {
+	var active []*Suppression
+	for _, s := range ws.index.suppressions {
+		if len(s.Problems(today())) == 0 {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// suppress splits violations into those no active suppression accepts and
+// those one does. Findings of the suppression rule itself are never
+// suppressed.
+func (ws *Workspace) suppress(violations []Violation) (kept []Violation, suppressed []Violation) {
+	active := ws.ActiveSuppressions()
+	for _, v := range violations {
+		if v.RuleID != suppressionRuleID && slices.ContainsFunc(active, func(s *Suppression) bool { return s.covers(v) }) {
+			suppressed = append(suppressed, v)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return kept, suppressed
+}
+
+func init() {
+	Register(&goRule{
+		id:          suppressionRuleID,
+		description: "Suppression comments must name known rules, give a reason and an unexpired expires date, and annotate a block or attribute",
+		severity:    SeverityError,
+		evaluate: func(ws *Workspace) []Violation {
+			const remediation = "write # " + suppressionMarker + " RULE-ID reason=\"why\" expires=YYYY-MM-DD on or above the block or attribute, or remove it"
+
+			var violations []Violation
+			for _, s := range ws.Suppressions() {
+				for _, problem := range s.Problems(today()) {
+					violations = append(violations, newViolation(s.Range, "", "suppression "+problem, remediation))
+				}
+			}
+			return violations
+		},
+	})
+}
+
+// parseSuppressions finds the suppression comments in a parsed file and
+// resolves the block or attribute each one annotates.
+func parseSuppressions(path string, content []byte, body *hclsyntax.Body) []*Suppression {
+	tokens, diags := hclsyntax.LexConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil
+	}
+
+	// codeLines records the lines holding anything other than comments and
+	// newlines, to tell trailing comments from comments on their own line.
+	codeLines := map[int]bool{}
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenComment, hclsyntax.TokenNewline, hclsyntax.TokenEOF:
+		default:
+			codeLines[tok.Range.Start.Line] = true
+		}
+	}
+
+	last := tokens[len(tokens)-1].Range.End.Line
+
+	var suppressions []*Suppression
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment {
+			continue
+		}
+
+		text := strings.TrimSpace(string(tok.Bytes))
+		text = strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(text, "#"), "//"), "/*")
+		text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+		args, ok := strings.CutPrefix(text, suppressionMarker)
+		if !ok {
+			continue
+		}
+
+		s := parseSuppression(args)
+		s.Range = tok.Range
+
+		// A comment on its own line annotates the next line holding code.
+		line := tok.Range.Start.Line
+		if !codeLines[line] {
+			line++
+			for line <= last && !codeLines[line] {
+				line++
+			}
+		}
+		if target, ok := itemStartingOn(body, line); ok {
+			s.Target = target
+		}
+
+		suppressions = append(suppressions, s)
+	}
+
+	return suppressions
+}
+
+// parseSuppression parses the text after the marker: comma-separated rule
+// IDs followed by reason="..." and expires=YYYY-MM-DD in any order.
+func parseSuppression(args string) *Suppression {
+	s := &Suppression{}
+
+	args = strings.TrimSpace(args)
+	ids, rest, _ := strings.Cut(args, " ")
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			s.Rules = append(s.Rules, id)
+		}
+	}
+	if len(s.Rules) == 0 {
+		s.syntaxErr = "names no rule"
+		return s
+	}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok || strings.ContainsAny(key, " \t") {
+			s.syntaxErr = fmt.Sprintf("cannot parse %q", rest)
+			return s
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				s.syntaxErr = fmt.Sprintf("unterminated %s value", key)
+				return s
+			}
+			rest = value[len(quoted):]
+			value, _ = strconv.Unquote(quoted)
+		} else {
+			value, rest, _ = strings.Cut(value, " ")
+		}
+
+		switch key {
+		case "reason":
+			s.Reason = value
+		case "expires":
+			expires, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				s.syntaxErr = fmt.Sprintf("expires %q is not a YYYY-MM-DD date", value)
+				return s
+			}
+			s.Expires = expires
+		default:
+			s.syntaxErr = fmt.Sprintf("unknown field %s", key)
+			return s
+		}
+	}
+
+	return s
+}
+
+// itemStartingOn returns the range of the innermost block or attribute that
+// starts on line.
+func itemStartingOn(body *hclsyntax.Body, line int) (hcl.Range, bool) {
+	for _, attr := range body.Attributes {
+		if attr.SrcRange.Start.Line == line {
+			return attr.SrcRange, true
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if block.DefRange().Start.Line == line {
+			return block.Range(), true
+		}
+		if rng := block.Range(); rng.Start.Line < line && line <= rng.End.Line {
+			return itemStartingOn(block.Body, line)
+		}
+	}
+
+	return hcl.Range{}, false
+}
@@ -0,0 +1,18 @@
+package policy
+
+// syntaxRuleID reports files that failed to parse. Every other rule reads the
+// parsed index, so a broken file would otherwise silently drop out of every
+// check; NewEngine therefore always selects it.
+const syntaxRuleID = "HCL-001"
+
+func init() {
+	Register(&goRule{
+		id:          syntaxRuleID,
+		description: "Terraform files must parse",
+		severity:    SeverityError,
+		traces:      []Trace{setupValidate.trace()},
+		evaluate: func(ws *Workspace) []Violation {
+			return append([]Violation(nil), ws.Diagnostics()...)
+		},
+	})
+}
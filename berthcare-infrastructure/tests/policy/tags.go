@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const defaultTagsRemediation = "add a default_tags { tags = { ... } } block to the aws provider"
+
+var missingProviderViolation = workspaceViolation("expected at least one aws provider to validate", "declare provider \"aws\" with default_tags")
+
+func init() {
+	Register(&goRule{
+		id:          "TAG-001",
+		description: "The aws provider default_tags must carry a Region tag of " + ExpectedRegion,
+		severity:    SeverityError,
+		traces:      []Trace{devTagging.trace("8.1"), stagingTagging.trace("9.1"), setupTagging.trace()},
+		plan:        true,
+		evaluate: func(ws *Workspace) []Violation {
+			if ws.PlanFile() != "" {
+				return checkBlocks(ws, ws.Blocks("resource"), checkPlannedRegionTag)
+			}
+			return requireBlocks(ws, ws.Providers("aws"), missingProviderViolation, checkDefaultTags)
+		},
+	})
+
+	Register(&goRule{
+		id:          "TAG-002",
+		description: "The aws provider default_tags must carry the environment's Project, Environment and Region",
+		severity:    SeverityError,
+		traces:      []Trace{devTagging.trace("8.2", "8.3"), stagingTagging.trace("9.2", "9.3")},
+		scope:       ScopeEnvironment,
+		evaluate:    evaluateProviderEnvironmentTags,
+	})
+}
+
+type tagExpectations struct {
+	project     string
+	environment string
+	region      string
+}
+
+func evaluateProviderEnvironmentTags(ws *Workspace) []Violation {
+	if _, _, err := ws.environmentAttributes("terraform.tfvars"); err != nil {
+		return []Violation{errorViolation(err)}
+	}
+
+	root := ws.Evaluator(ws.RootModule())
+	expected := tagExpectations{
+		project:     "berthcare",
+		environment: ws.Environment,
+		region:      ExpectedRegion,
+	}
+	if project, ok := knownString(root.Variable("project_name")); ok {
+		expected.project = project
+	}
+	if environment, ok := knownString(root.Variable("environment")); ok {
+		expected.environment = environment
+	}
+
+	return requireBlocks(ws, ws.Providers("aws"), missingProviderViolation, func(block *Block) []Violation {
+		return checkInstances(ws, block, func(ev *Evaluator, address string) []Violation {
+			return checkProviderDefaultTags(block, ev, address, expected)
+		})
+	})
+}
+
+func checkDefaultTags(block *Block) []Violation {
+	var violations []Violation
+	hasDefaultTags := false
+	address := block.Address
+
+	for _, child := range block.Body.Blocks {
+		if child.Type != "default_tags" {
+			continue
+		}
+
+		hasDefaultTags = true
+		tagsAttr, ok := child.Body.Attributes["tags"]
+		if !ok {
+			violations = append(violations, newViolation(child.DefRange(), address, "default_tags must define tags map", defaultTagsRemediation))
+			continue
+		}
+
+		violations = append(violations, ensureRegionTag(address, tagsAttr)...)
+	}
+
+	if !hasDefaultTags {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws provider missing default_tags block", defaultTagsRemediation))
+	}
+
+	return violations
+}
+
+func ensureRegionTag(address string, tagsAttr *hclsyntax.Attribute) []Violation {
+	const remediation = "set Region = \"" + ExpectedRegion + "\" in default_tags"
+
+	cons, ok := tagsAttr.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return []Violation{newViolation(tagsAttr.Range(), address, "tags must be an object literal to validate region tag", "write tags as an object literal")}
+	}
+
+	var violations []Violation
+	foundRegion := false
+
+	for _, item := range cons.Items {
+		keyVal, diag := constValue(item.KeyExpr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(item.KeyExpr.Range(), address, fmt.Sprintf("tag key must be a constant string (%s)", diag.Error()), "use literal tag keys"))
+			continue
+		}
+
+		if keyVal.Type() != cty.String {
+			violations = append(violations, newViolation(item.KeyExpr.Range(), address, "tag key must be a string literal", "use literal tag keys"))
+			continue
+		}
+
+		key := keyVal.AsString()
+		if key != "Region" {
+			continue
+		}
+
+		foundRegion = true
+		value, diag := constValue(item.ValueExpr)
+		if diag.HasErrors() {
+			violations = append(violations, newViolation(item.ValueExpr.Range(), address, fmt.Sprintf("Region tag must be a constant string (%s)", diag.Error()), remediation))
+			continue
+		}
+
+		if value.Type() != cty.String {
+			violations = append(violations, newViolation(item.ValueExpr.Range(), address, "Region tag must be a string literal", remediation))
+			continue
+		}
+
+		if value.AsString() != ExpectedRegion {
+			violations = append(violations, newViolation(item.ValueExpr.Range(), address, fmt.Sprintf("Region tag set to %s (expected %s)", value.AsString(), ExpectedRegion), remediation))
+		}
+	}
+
+	if !foundRegion {
+		violations = append(violations, newViolation(tagsAttr.Range(), address, "default_tags is missing Region tag", remediation))
+	}
+
+	return violations
+}
+
+// checkPlannedRegionTag checks the tags_all a plan gives a resource, which
+// merges the provider's default_tags into the resource's own tags. A plan
+// records no provider values, so this is where the default tags show.
+// Resources without tags_all are not taggable.
+func checkPlannedRegionTag(block *Block) []Violation {
+	const remediation = "set Region = \"" + ExpectedRegion + "\" in the aws provider default_tags"
+
+	attr, ok := block.Body.Attributes["tags_all"]
+	if !ok {
+		return nil
+	}
+
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() || !val.Type().IsObjectType() {
+		return nil
+	}
+
+	if !val.Type().HasAttribute("Region") {
+		return []Violation{newViolation(attr.Range(), block.Address, "planned tags_all is missing Region tag", remediation)}
+	}
+
+	region, ok := knownString(val.GetAttr("Region"))
+	if !ok {
+		return []Violation{newViolation(attr.Range(), block.Address, "planned Region tag must be a string", remediation)}
+	}
+	if region != ExpectedRegion {
+		return []Violation{newViolation(attr.Range(), block.Address, fmt.Sprintf("planned Region tag is %s (expected %s)", region, ExpectedRegion), remediation)}
+	}
+
+	return nil
+}
+
+func checkProviderDefaultTags(block *Block, ev *Evaluator, address string, expected tagExpectations) []Violation {
+	var violations []Violation
+	hasDefaultTags := false
+
+	for _, child := range block.Body.Blocks {
+		if child.Type != "default_tags" {
+			continue
+		}
+
+		hasDefaultTags = true
+		tagsAttr, ok := child.Body.Attributes["tags"]
+		if !ok {
+			violations = append(violations, newViolation(child.DefRange(), address, "default_tags must define tags map", defaultTagsRemediation))
+			continue
+		}
+
+		cons, ok := tagsAttr.Expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			violations = append(violations, newViolation(tagsAttr.Range(), address, "tags must be an object literal", "write tags as an object literal"))
+			continue
+		}
+
+		violations = append(violations, ensureTagValue(address, cons, "Project", expected.project, ev)...)
+		violations = append(violations, ensureTagValue(address, cons, "Environment", expected.environment, ev)...)
+		violations = append(violations, ensureTagValue(address, cons, "Region", expected.region, ev)...)
+	}
+
+	if !hasDefaultTags {
+		violations = append(violations, newViolation(block.DefRange(), address, "aws provider missing default_tags block", defaultTagsRemediation))
+	}
+
+	return violations
+}
+
+func ensureTagValue(address string, cons *hclsyntax.ObjectConsExpr, key string, expected string, ev *Evaluator) []Violation {
+	remediation := fmt.Sprintf("set %s = %q in default_tags", key, expected)
+
+	for _, item := range cons.Items {
+		keyVal, diag := constValue(item.KeyExpr)
+		if diag.HasErrors() {
+			return []Violation{newViolation(item.KeyExpr.Range(), address, fmt.Sprintf("tag key must be a constant string (%s)", diag.Error()), "use literal tag keys")}
+		}
+
+		if keyVal.Type() != cty.String {
+			return []Violation{newViolation(item.KeyExpr.Range(), address, "tag key must be a string literal", "use literal tag keys")}
+		}
+
+		if keyVal.AsString() != key {
+			continue
+		}
+
+		val, diag := ev.Value(item.ValueExpr)
+		if diag.HasErrors() {
+			return []Violation{newViolation(item.ValueExpr.Range(), address, fmt.Sprintf("%s tag must be a resolvable string (%s)", key, diag.Error()), remediation)}
+		}
+
+		str, ok := knownString(val)
+		if !ok {
+			return []Violation{newViolation(item.ValueExpr.Range(), address, fmt.Sprintf("%s tag must be a string known before apply", key), remediation)}
+		}
+
+		if str != expected {
+			return []Violation{newViolation(item.ValueExpr.Range(), address, fmt.Sprintf("%s tag set to %s (expected %s)", key, str, expected), remediation)}
+		}
+
+		return nil
+	}
+
+	return []Violation{newViolation(cons.Range(), address, fmt.Sprintf("default_tags missing %s tag", key), remediation)}
+}
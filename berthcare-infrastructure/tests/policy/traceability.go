@@ -0,0 +1,430 @@
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Property is a correctness property from a feature's design, such as
+// Property 2: RDS Security Compliance of aws-dev-environment. Number is zero
+// for a property the design does not number.
+type Property struct {
+	Feature string
+	Number  int
+	Name    string
+}
+
+func (p Property) String() string {
+	if p.Number == 0 {
+		return p.Feature + ", " + p.Name
+	}
+	return fmt.Sprintf("%s, Property %d: %s", p.Feature, p.Number, p.Name)
+}
+
+func (p Property) key() string {
+	return p.Feature + "\x00" + p.Name
+}
+
+// Trace ties a rule to a property it enforces and the feature's requirements
+// it satisfies.
+type Trace struct {
+	Property     Property
+	Requirements []string
+}
+
+func (p Property) trace(requirements ...string) Trace {
+	return Trace{Property: p, Requirements: requirements}
+}
+
+// tracedRule is implemented by rules that declare the properties they
+// enforce.
+type tracedRule interface {
+	Traces() []Trace
+}
+
+// Traces returns the properties and requirements rule declares.
+func Traces(rule Rule) []Trace {
+	if r, ok := rule.(tracedRule); ok {
+		return r.Traces()
+	}
+	return nil
+}
+
+const (
+	featureDev     = "aws-dev-environment"
+	featureStaging = "aws-staging-environment"
+	featureSetup   = "infrastructure-repository-setup"
+)
+
+// The properties rules trace to, numbered as in each feature's design.
+var (
+	devRegion       = Property{featureDev, 1, "Regional Compliance"}
+	devRDS          = Property{featureDev, 2, "RDS Security Compliance"}
+	devS3           = Property{featureDev, 3, "S3 Security Compliance"}
+	devECS          = Property{featureDev, 4, "ECS Private Subnet Placement"}
+	devBackend      = Property{featureDev, 5, "State Backend Configuration"}
+	devTagging      = Property{featureDev, 6, "Resource Tagging Compliance"}
+	stagingRegion   = Property{featureStaging, 1, "Regional Compliance"}
+	stagingRDS      = Property{featureStaging, 2, "RDS Security Compliance"}
+	stagingS3       = Property{featureStaging, 3, "S3 Security Compliance"}
+	stagingALB      = Property{featureStaging, 4, "ALB HTTPS Configuration"}
+	stagingECS      = Property{featureStaging, 5, "ECS Private Subnet Placement"}
+	stagingDNS      = Property{featureStaging, 6, "DNS Configuration"}
+	stagingACM      = Property{featureStaging, 7, "ACM Certificate Configuration"}
+	stagingBackend  = Property{featureStaging, 8, "State Backend Configuration"}
+	stagingTagging  = Property{featureStaging, 9, "Resource Tagging Compliance"}
+	setupRegion     = Property{featureSetup, 1, "Regional Compliance"}
+	setupTagging    = Property{featureSetup, 2, "Resource Tagging Compliance"}
+	setupRDS        = Property{featureSetup, 3, "RDS Security Compliance"}
+	setupS3         = Property{featureSetup, 4, "S3 Security Compliance"}
+	setupECS        = Property{featureSetup, 5, "ECS Private Subnet Placement"}
+	setupValidate   = Property{featureSetup, 6, "Terraform Validation Success"}
+	setupPlanRegion = Property{featureSetup, 8, "Plan Region Constraint"}
+	setupVPCOutputs = Property{featureSetup, 0, "VPC outputs defined"}
+)
+
+// Annotation is a property a test declares it checks, from a
+//
+//	// **Feature: NAME, Property N: TITLE**
+//	// **Validates: Requirements X.Y, ...**
+//
+// doc comment pair or a t.Run("Feature: NAME, Property N: TITLE") subtest.
+// A Validates line applies to every Feature line since the previous one.
+type Annotation struct {
+	Test         string
+	File         string
+	Line         int
+	Property     Property
+	Requirements []string
+}
+
+var (
+	featureCommentPattern   = regexp.MustCompile(`^\*\*Feature: ([\w-]+), Property (\d+): (.+?)\*\*$`)
+	validatesCommentPattern = regexp.MustCompile(`^\*\*Validates: Requirements? (.+?)\*\*$`)
+	propertyRunPattern      = regexp.MustCompile(`^Feature: ([\w-]+), Property (\d+): (.+)$`)
+	requirementRunPattern   = regexp.MustCompile(`^Feature: ([\w-]+), (.+?) \(Requirements? (.+)\)$`)
+)
+
+// ScanAnnotations reads the annotations of every test in the _test.go files
+// directly in dir.
+func ScanAnnotations(dir string) ([]Annotation, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fset := token.NewFileSet()
+	var annotations []Annotation
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			annotations = append(annotations, commentAnnotations(fset, fn)...)
+			annotations = append(annotations, runAnnotations(fset, fn)...)
+		}
+	}
+	return annotations, nil
+}
+
+func commentAnnotations(fset *token.FileSet, fn *ast.FuncDecl) []Annotation {
+	if fn.Doc == nil {
+		return nil
+	}
+
+	var annotations []Annotation
+	pending := 0
+	for _, comment := range fn.Doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if m := featureCommentPattern.FindStringSubmatch(text); m != nil {
+			number, _ := strconv.Atoi(m[2])
+			pos := fset.Position(comment.Pos())
+			annotations = append(annotations, Annotation{Test: fn.Name.Name, File: pos.Filename, Line: pos.Line, Property: Property{m[1], number, m[3]}})
+			pending++
+			continue
+		}
+		if m := validatesCommentPattern.FindStringSubmatch(text); m != nil {
+			requirements := splitRequirements(m[1])
+			for i := len(annotations) - pending; i < len(annotations); i++ {
+				annotations[i].Requirements = requirements
+			}
+			pending = 0
+		}
+	}
+	return annotations
+}
+
+func runAnnotations(fset *token.FileSet, fn *ast.FuncDecl) []Annotation {
+	var annotations []Annotation
+	ast.Inspect(fn, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Run" {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		name, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+
+		annotation := Annotation{Test: fn.Name.Name}
+		if m := requirementRunPattern.FindStringSubmatch(name); m != nil {
+			annotation.Property = Property{Feature: m[1], Name: m[2]}
+			annotation.Requirements = splitRequirements(m[3])
+		} else if m := propertyRunPattern.FindStringSubmatch(name); m != nil {
+			number, _ := strconv.Atoi(m[2])
+			annotation.Property = Property{m[1], number, m[3]}
+		} else {
+			return true
+		}
+		pos := fset.Position(lit.Pos())
+		annotation.File, annotation.Line = pos.Filename, pos.Line
+		annotations = append(annotations, annotation)
+		return true
+	})
+	return annotations
+}
+
+func splitRequirements(list string) []string {
+	var requirements []string
+	for _, requirement := range strings.Split(list, ",") {
+		if requirement = strings.TrimSpace(requirement); requirement != "" {
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements
+}
+
+// Requirement is a requirement of one feature, such as 2.3 of
+// aws-dev-environment.
+type Requirement struct {
+	Feature string
+	ID      string
+}
+
+func (r Requirement) String() string {
+	return r.Feature + " " + r.ID
+}
+
+// MatrixRow is one property: the rules that enforce it, the tests that
+// check it, and the requirements the rules satisfy.
+type MatrixRow struct {
+	Property     Property
+	Rules        []string
+	Tests        []string
+	Requirements []string
+}
+
+// Matrix traces requirements through the rules that satisfy them to the
+// tests that validate them.
+type Matrix struct {
+	Rows []MatrixRow
+	// Covered holds the requirements a rule satisfies that a test validates.
+	Covered []Requirement
+	// Unvalidated holds the requirements a rule satisfies that no test
+	// validates.
+	Unvalidated []Requirement
+	// Untested holds the properties a rule enforces that no test checks.
+	Untested []Property
+	// Untraced holds the IDs of rules that declare no property.
+	Untraced []string
+}
+
+// BuildMatrix joins the properties rules declare with the annotations tests
+// carry. A test validates a requirement for its whole feature, whichever
+// property it lists the requirement under.
+func BuildMatrix(rules []Rule, annotations []Annotation) *Matrix {
+	m := &Matrix{}
+	rows := map[string]*MatrixRow{}
+	row := func(p Property) *MatrixRow {
+		if r, ok := rows[p.key()]; ok {
+			if r.Property.Number == 0 {
+				r.Property.Number = p.Number
+			}
+			return r
+		}
+		rows[p.key()] = &MatrixRow{Property: p}
+		return rows[p.key()]
+	}
+
+	for _, rule := range rules {
+		traces := Traces(rule)
+		if len(traces) == 0 {
+			m.Untraced = append(m.Untraced, rule.ID())
+		}
+		for _, trace := range traces {
+			r := row(trace.Property)
+			r.Rules = appendUnique(r.Rules, rule.ID())
+			for _, requirement := range trace.Requirements {
+				r.Requirements = appendUnique(r.Requirements, requirement)
+			}
+		}
+	}
+
+	validated := map[Requirement]bool{}
+	for _, annotation := range annotations {
+		r := row(annotation.Property)
+		r.Tests = appendUnique(r.Tests, annotation.Test)
+		for _, requirement := range annotation.Requirements {
+			validated[Requirement{annotation.Property.Feature, requirement}] = true
+		}
+	}
+
+	for _, r := range rows {
+		sort.Strings(r.Rules)
+		sort.Strings(r.Tests)
+		sort.Slice(r.Requirements, func(i, j int) bool { return requirementLess(r.Requirements[i], r.Requirements[j]) })
+		m.Rows = append(m.Rows, *r)
+	}
+	sort.Slice(m.Rows, func(i, j int) bool {
+		a, b := m.Rows[i].Property, m.Rows[j].Property
+		if a.Feature != b.Feature {
+			return a.Feature < b.Feature
+		}
+		if a.Number != b.Number {
+			// Unnumbered properties follow the numbered ones.
+			return b.Number == 0 || a.Number != 0 && a.Number < b.Number
+		}
+		return a.Name < b.Name
+	})
+
+	seen := map[Requirement]bool{}
+	for _, r := range m.Rows {
+		if len(r.Rules) > 0 && len(r.Tests) == 0 {
+			m.Untested = append(m.Untested, r.Property)
+		}
+		for _, id := range r.Requirements {
+			requirement := Requirement{r.Property.Feature, id}
+			if seen[requirement] {
+				continue
+			}
+			seen[requirement] = true
+			if validated[requirement] {
+				m.Covered = append(m.Covered, requirement)
+			} else {
+				m.Unvalidated = append(m.Unvalidated, requirement)
+			}
+		}
+	}
+	sortRequirements(m.Covered)
+	sortRequirements(m.Unvalidated)
+	return m
+}
+
+// WriteMarkdown writes the matrix as a Markdown document.
+func (m *Matrix) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# Requirements traceability\n\n")
+	fmt.Fprintf(&sb, "Requirements covered: %d. Never validated: %d. Properties without a test: %d. Rules without a property: %d.\n\n",
+		len(m.Covered), len(m.Unvalidated), len(m.Untested), len(m.Untraced))
+
+	sb.WriteString("| Feature | Property | Rules | Tests | Requirements |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- |\n")
+	unvalidated := map[Requirement]bool{}
+	for _, requirement := range m.Unvalidated {
+		unvalidated[requirement] = true
+	}
+	for _, r := range m.Rows {
+		property := r.Property.Name
+		if r.Property.Number != 0 {
+			property = fmt.Sprintf("%d: %s", r.Property.Number, r.Property.Name)
+		}
+		var requirements []string
+		for _, id := range r.Requirements {
+			if unvalidated[Requirement{r.Property.Feature, id}] {
+				id += " (not validated)"
+			}
+			requirements = append(requirements, id)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", r.Property.Feature, property, markdownList(r.Rules), markdownList(r.Tests), markdownList(requirements))
+	}
+
+	writeSection := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+	}
+	writeSection("Requirements never validated", stringsOf(m.Unvalidated))
+	writeSection("Properties without a test", stringsOf(m.Untested))
+	writeSection("Rules without a property", m.Untraced)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownList(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
+
+func stringsOf[T fmt.Stringer](items []T) []string {
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		strs = append(strs, item.String())
+	}
+	return strs
+}
+
+func appendUnique(items []string, item string) []string {
+	if slices.Contains(items, item) {
+		return items
+	}
+	return append(items, item)
+}
+
+func sortRequirements(requirements []Requirement) {
+	sort.Slice(requirements, func(i, j int) bool {
+		if requirements[i].Feature != requirements[j].Feature {
+			return requirements[i].Feature < requirements[j].Feature
+		}
+		return requirementLess(requirements[i].ID, requirements[j].ID)
+	})
+}
+
+// requirementLess orders requirement IDs numerically part by part, so that
+// 2.10 follows 2.9.
+func requirementLess(a string, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		if aErr != nil || bErr != nil {
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+			continue
+		}
+		if an != bn {
+			return an < bn
+		}
+	}
+	return len(as) < len(bs)
+}
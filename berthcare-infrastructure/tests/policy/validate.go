@@ -0,0 +1,230 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// validateRuleID reports the diagnostics of terraform validate. It is not a
+// registered rule: Validate runs terraform, which check never does.
+const validateRuleID = "TF-001"
+
+// Validator runs terraform validate against a configuration without touching
+// it or the network. Each module is validated in a copy of the configuration
+// in a temporary directory, initialised from a local provider mirror.
+type Validator struct {
+	// Terraform is the terraform binary to run, "terraform" when empty.
+	Terraform string
+	// PluginDir is a provider mirror, as written by terraform providers
+	// mirror, that terraform init installs providers from instead of a
+	// registry.
+	PluginDir string
+	// Timeout bounds each terraform command; zero means no limit.
+	Timeout time.Duration
+}
+
+// ModuleValidation is what terraform validate made of one module.
+type ModuleValidation struct {
+	// Dir is the module's directory relative to the configuration root, "."
+	// for the root module.
+	Dir        string
+	Violations []Violation
+}
+
+// validateJSON is the part of terraform validate -json's output Validate
+// reads.
+type validateJSON struct {
+	Diagnostics []validateDiagnostic `json:"diagnostics"`
+}
+
+type validateDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Address  string `json:"address"`
+	Range    *struct {
+		Filename string  `json:"filename"`
+		Start    hcl.Pos `json:"start"`
+		End      hcl.Pos `json:"end"`
+	} `json:"range"`
+}
+
+// Validate validates the root module at root and every module under its
+// modules directory, each on its own, and returns the diagnostics as
+// violations located in root's files. An error means terraform could not be
+// run to completion, not that the configuration is invalid.
+func (v Validator) Validate(ctx context.Context, root string) ([]ModuleValidation, error) {
+	if v.PluginDir == "" {
+		return nil, errors.New("validating offline needs a provider mirror to install providers from")
+	}
+	modules, err := terraformModules(root)
+	if err != nil {
+		return nil, err
+	}
+	pluginDir, err := filepath.Abs(v.PluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	copied, err := os.MkdirTemp("", "berthcare-validate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(copied)
+	if err := copyConfiguration(root, copied); err != nil {
+		return nil, err
+	}
+
+	results := make([]ModuleValidation, 0, len(modules))
+	for _, dir := range modules {
+		work := filepath.Join(copied, dir)
+		if _, err := v.terraform(ctx, work, "init", "-backend=false", "-input=false", "-no-color", "-plugin-dir="+pluginDir); err != nil {
+			return nil, fmt.Errorf("module %s: %w", dir, err)
+		}
+		// validate exits 1 when it finds errors but still prints them, so
+		// its output decides rather than its exit status.
+		out, runErr := v.terraform(ctx, work, "validate", "-json", "-no-color")
+		var parsed validateJSON
+		if err := json.Unmarshal(out, &parsed); err != nil {
+			if runErr != nil {
+				err = runErr
+			}
+			return nil, fmt.Errorf("module %s: terraform validate: %w", dir, err)
+		}
+
+		result := ModuleValidation{Dir: dir}
+		for _, diag := range parsed.Diagnostics {
+			result.Violations = append(result.Violations, diag.violation(filepath.Join(root, dir)))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// violation reports the diagnostic against the files of the module in dir.
+func (d validateDiagnostic) violation(dir string) Violation {
+	message := d.Summary
+	if d.Detail != "" {
+		message += "; " + d.Detail
+	}
+	location := hcl.Range{}
+	if d.Range != nil {
+		location = hcl.Range{Filename: filepath.Join(dir, filepath.FromSlash(d.Range.Filename)), Start: d.Range.Start, End: d.Range.End}
+	}
+
+	v := newViolation(location, d.Address, message, "fix the configuration so that terraform validate accepts it")
+	v.RuleID = validateRuleID
+	v.Severity = SeverityError
+	if d.Severity == "warning" {
+		v.Severity = SeverityWarning
+	}
+	return v
+}
+
+// terraform runs one terraform command in dir within the validator's timeout
+// and returns its standard output.
+func (v Validator) terraform(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	if v.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.Timeout)
+		defer cancel()
+	}
+	binary := v.Terraform
+	if binary == "" {
+		binary = "terraform"
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1", "TF_INPUT=0", "CHECKPOINT_DISABLE=1")
+	// A provider plugin outliving a killed terraform can hold its output
+	// open; stop waiting for it.
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+
+	name := "terraform " + args[0]
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return out, fmt.Errorf("%s did not finish within %s", name, v.Timeout)
+	case ctx.Err() != nil:
+		return out, ctx.Err()
+	case err != nil:
+		output := strings.TrimSpace(stderr.String())
+		if output == "" {
+			output = strings.TrimSpace(string(out))
+		}
+		return out, fmt.Errorf("%s: %v: %s", name, err, output)
+	}
+	return out, nil
+}
+
+// terraformModules returns the directories, relative to root, of the root
+// module and of every module under root's modules directory, in order.
+func terraformModules(root string) ([]string, error) {
+	modules := []string{"."}
+	files, err := collectFiles(filepath.Join(root, "modules"), ".tf")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, file := range files {
+		dir, err := filepath.Rel(root, filepath.Dir(file))
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(modules, dir) {
+			modules = append(modules, dir)
+		}
+	}
+	slices.Sort(modules[1:])
+	return modules, nil
+}
+
+// copyConfiguration copies the files under root to dir, leaving out what
+// terraform init left behind and what collectFiles never reads.
+func copyConfiguration(root string, dir string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+
+		if d.IsDir() {
+			if path != root {
+				switch d.Name() {
+				case ".git", ".terraform", "node_modules", "vendor", "testdata":
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		// The lock file pins provider hashes from wherever it was last
+		// written; the mirror decides which providers are used instead.
+		if !d.Type().IsRegular() || d.Name() == ".terraform.lock.hcl" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+}
@@ -0,0 +1,124 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Violation is a single finding reported by a rule.
+type Violation struct {
+	RuleID   string
+	Severity Severity
+	// Environment is the environment the rule was evaluated for, empty when
+	// it was evaluated against the configuration alone.
+	Environment string
+	// Resource is the Terraform address of the offending block, such as
+	// aws_lb_listener.https or provider.aws. It is empty for findings that
+	// are not tied to a block.
+	Resource string
+	// Range locates the finding. Range.Filename is empty when the finding is
+	// not tied to a file, and the positions are zero when it is tied to a file
+	// as a whole.
+	Range       hcl.Range
+	Message     string
+	Remediation string
+	// Fix resolves the violation when the rule knows how, and is nil
+	// otherwise.
+	Fix *Fix
+}
+
+// Location renders the violation's range as path:line:column, or as much of
+// it as is known.
+func (v Violation) Location() string {
+	switch {
+	case v.Range.Filename == "":
+		return ""
+	case v.Range.Start.Line == 0:
+		return v.Range.Filename
+	default:
+		return fmt.Sprintf("%s:%d:%d", v.Range.Filename, v.Range.Start.Line, v.Range.Start.Column)
+	}
+}
+
+func (v Violation) String() string {
+	var sb strings.Builder
+	if loc := v.Location(); loc != "" {
+		sb.WriteString(loc)
+		sb.WriteString(": ")
+	}
+	if v.Environment != "" {
+		fmt.Fprintf(&sb, "[%s %s] ", v.RuleID, v.Environment)
+	} else {
+		fmt.Fprintf(&sb, "[%s] ", v.RuleID)
+	}
+	if v.Resource != "" {
+		sb.WriteString(v.Resource)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(v.Message)
+	return sb.String()
+}
+
+func newViolation(rng hcl.Range, resource string, message string, remediation string) Violation {
+	return Violation{
+		Resource:    resource,
+		Range:       rng,
+		Message:     message,
+		Remediation: remediation,
+	}
+}
+
+// fileViolation reports a finding against a whole file.
+func fileViolation(filename string, message string, remediation string) Violation {
+	return newViolation(hcl.Range{Filename: filename}, "", message, remediation)
+}
+
+// workspaceViolation reports a finding that is not tied to any file, such as
+// a resource the rule expected but could not find anywhere.
+func workspaceViolation(message string, remediation string) Violation {
+	return Violation{Message: message, Remediation: remediation}
+}
+
+func errorViolation(err error) Violation {
+	var fileErr *environmentFileError
+	if errors.As(err, &fileErr) {
+		return newViolation(fileErr.rng, "", fileErr.message, fileErr.remediation)
+	}
+	return Violation{Message: err.Error()}
+}
+
+func diagnosticViolations(filename string, diags hcl.Diagnostics) []Violation {
+	var violations []Violation
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		rng := hcl.Range{Filename: filename}
+		if diag.Subject != nil {
+			rng = *diag.Subject
+		}
+		violations = append(violations, newViolation(rng, "", fmt.Sprintf("unable to parse HCL: %s; %s", diag.Summary, diag.Detail), "fix the syntax error so the file can be parsed"))
+	}
+	return violations
+}
+
+// blockAddress returns the Terraform address of a top-level block.
+func blockAddress(block *hclsyntax.Block) string {
+	switch block.Type {
+	case "resource":
+		if len(block.Labels) >= 2 {
+			return block.Labels[0] + "." + block.Labels[1]
+		}
+	case "variable":
+		if len(block.Labels) >= 1 {
+			return "var." + block.Labels[0]
+		}
+	case "locals":
+		return "local"
+	}
+	return strings.Join(append([]string{block.Type}, block.Labels...), ".")
+}
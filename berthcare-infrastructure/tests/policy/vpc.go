@@ -0,0 +1,40 @@
+package policy
+
+import "fmt"
+
+const vpcModule = "modules/vpc"
+
+var requiredVPCOutputs = []string{
+	"vpc_id",
+	"public_subnet_ids",
+	"private_subnet_ids",
+	"nat_gateway_ids",
+}
+
+func init() {
+	Register(&goRule{
+		id:          "VPC-001",
+		description: "The VPC module must export its VPC, subnet and NAT gateway IDs",
+		severity:    SeverityError,
+		traces:      []Trace{setupVPCOutputs.trace("3.6")},
+		evaluate:    evaluateVPCOutputs,
+	})
+}
+
+func evaluateVPCOutputs(ws *Workspace) []Violation {
+	defined := map[string]bool{}
+	for _, block := range ws.Blocks("output") {
+		if block.Module == vpcModule && len(block.Labels) > 0 {
+			defined[block.Labels[0]] = true
+		}
+	}
+
+	var violations []Violation
+	for _, name := range requiredVPCOutputs {
+		if !defined[name] {
+			violations = append(violations, workspaceViolation(fmt.Sprintf("%s missing required VPC output %s", vpcModule, name), fmt.Sprintf("add output %q to %s/outputs.tf", name, vpcModule)))
+		}
+	}
+
+	return violations
+}
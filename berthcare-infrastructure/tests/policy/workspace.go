@@ -0,0 +1,400 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ExpectedRegion is the only AWS region BerthCare infrastructure may use.
+const ExpectedRegion = "ca-central-1"
+
+// Workspace is a Terraform tree parsed once and indexed for rules to query,
+// optionally evaluated for one of the environments under environments/.
+type Workspace struct {
+	Root        string
+	Environment string
+	Files       []*File
+	// DestroyGuard configures which destructive changes a plan may make.
+	DestroyGuard DestroyGuard
+
+	// focus, when not nil, holds the qualified addresses of the only blocks
+	// rules check. See focusOn.
+	focus map[string]bool
+	index *index
+}
+
+// File is a parsed Terraform file. Body is nil when the file failed to parse.
+type File struct {
+	Path   string
+	Module string
+	Body   *hclsyntax.Body
+}
+
+// Block is a top-level block of a Terraform file.
+type Block struct {
+	*hclsyntax.Block
+	File *File
+	// Module is the directory holding the block, relative to the workspace
+	// root, such as "." or "modules/rds".
+	Module string
+	// Address is the block's address within its module, such as
+	// aws_lb_listener.https, data.aws_iam_policy_document.photos or
+	// provider.aws.
+	Address string
+}
+
+type index struct {
+	byKind       map[string][]*Block
+	byAddress    map[string][]*Block
+	diagnostics  []Violation
+	suppressions []*Suppression
+	root         *ModuleInstance
+	instances    map[string][]*ModuleInstance
+
+	// plan and state are the plan or state file a rendered workspace was
+	// loaded from, and renderedModules maps the name of each module
+	// rendering to its address.
+	plan            string
+	state           string
+	renderedModules map[string]string
+	changes         []ResourceChange
+	stateResources  []StateResource
+
+	mu         sync.Mutex
+	attributes map[string]attributeFile
+
+	evalMu      sync.Mutex
+	evaluations map[string]*evaluation
+}
+
+type attributeFile struct {
+	attrs hclsyntax.Attributes
+	err   error
+}
+
+// LoadWorkspace walks root once, parses every Terraform file, indexes the
+// top-level blocks and resolves the module call tree from the root module.
+// Files that fail to parse are kept with a nil Body and reported through
+// Diagnostics.
+func LoadWorkspace(root string) (*Workspace, error) {
+	return loadWorkspace(root, nil)
+}
+
+// loadWorkspace is LoadWorkspace taking the content of the files named in
+// overrides from there rather than from disk.
+func loadWorkspace(root string, overrides map[string][]byte) (*Workspace, error) {
+	paths, err := collectTerraformFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{
+		Root: root,
+		index: &index{
+			byKind:      map[string][]*Block{},
+			byAddress:   map[string][]*Block{},
+			attributes:  map[string]attributeFile{},
+			evaluations: map[string]*evaluation{},
+		},
+	}
+
+	for _, path := range paths {
+		content, ok := overrides[path]
+		if !ok {
+			if content, err = os.ReadFile(path); err != nil {
+				return nil, err
+			}
+		}
+		ws.addFile(path, content)
+	}
+	ws.buildModuleTree()
+
+	return ws, nil
+}
+
+// WithEnvironment returns a view of the workspace evaluated for the named
+// environment. The parsed files and index are shared, not copied.
+func (ws *Workspace) WithEnvironment(name string) *Workspace {
+	view := *ws
+	view.Environment = name
+	return &view
+}
+
+// focusOn returns a view of the workspace in which rules check only the
+// blocks with the given qualified addresses. Rules still read any other
+// block they need, such as the security groups an RDS instance uses, and a
+// rule that expects at least one block still counts the unchecked ones.
+func (ws *Workspace) focusOn(addresses []string) *Workspace {
+	view := *ws
+	view.focus = map[string]bool{}
+	for _, address := range addresses {
+		view.focus[address] = true
+	}
+	return &view
+}
+
+// inFocus reports whether rules check block: whether the workspace has no
+// focus or the block has a qualified address in it.
+func (ws *Workspace) inFocus(block *Block) bool {
+	if ws.focus == nil {
+		return true
+	}
+	for _, inst := range ws.Instances(block.Module) {
+		for _, node := range blockNodes(inst, block) {
+			if ws.focus[node] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Environments returns the name of every directory under environments/,
+// sorted. A workspace without an environments directory, or loaded from a
+// plan or state file, has none.
+func (ws *Workspace) Environments() ([]string, error) {
+	if ws.renderedFile() != "" {
+		return nil, nil
+	}
+	return ListEnvironments(ws.Root)
+}
+
+// ListEnvironments returns the name of every directory under the
+// environments directory of the configuration at root, sorted, without
+// loading the configuration.
+func ListEnvironments(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "environments"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (ws *Workspace) addFile(path string, content []byte) {
+	module := "."
+	if rel, err := filepath.Rel(ws.Root, filepath.Dir(path)); err == nil {
+		module = filepath.ToSlash(rel)
+	}
+
+	file := &File{Path: path, Module: module}
+	ws.Files = append(ws.Files, file)
+
+	body, violations := parseBody(path, content)
+	if body == nil {
+		ws.index.diagnostics = append(ws.index.diagnostics, violations...)
+		return
+	}
+	ws.index.suppressions = append(ws.index.suppressions, parseSuppressions(path, content, body)...)
+	ws.addBody(file, body)
+}
+
+// addBody indexes the top-level blocks of a parsed file.
+func (ws *Workspace) addBody(file *File, body *hclsyntax.Body) {
+	file.Body = body
+
+	for _, hclBlock := range body.Blocks {
+		block := &Block{
+			Block:   hclBlock,
+			File:    file,
+			Module:  file.Module,
+			Address: blockAddress(hclBlock),
+		}
+		ws.index.byKind[hclBlock.Type] = append(ws.index.byKind[hclBlock.Type], block)
+		ws.index.byAddress[block.Address] = append(ws.index.byAddress[block.Address], block)
+	}
+}
+
+func collectTerraformFiles(root string) ([]string, error) {
+	return collectFiles(root, ".tf")
+}
+
+// collectFiles returns the files under root with one of the given
+// extensions, skipping tool, dependency and test fixture directories.
+func collectFiles(root string, extensions ...string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			// testdata holds the fixtures of the policy tests, which are
+			// deliberately broken configurations of their own.
+			switch d.Name() {
+			case ".git", ".terraform", "node_modules", "vendor", "testdata":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if slices.Contains(extensions, filepath.Ext(d.Name())) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// Diagnostics returns a violation for every file that failed to parse.
+func (ws *Workspace) Diagnostics() []Violation {
+	return ws.index.diagnostics
+}
+
+// Blocks returns every top-level block of the given kind, such as resource,
+// data, module, variable, output, provider, terraform or locals.
+func (ws *Workspace) Blocks(kind string) []*Block {
+	return ws.index.byKind[kind]
+}
+
+// Lookup returns the blocks with the given address in any module.
+func (ws *Workspace) Lookup(address string) []*Block {
+	return ws.index.byAddress[address]
+}
+
+// Resources returns every resource block of the given type.
+func (ws *Workspace) Resources(resourceType string) []*Block {
+	return ws.labelled("resource", resourceType, 2)
+}
+
+// DataSources returns every data block of the given type.
+func (ws *Workspace) DataSources(dataType string) []*Block {
+	return ws.labelled("data", dataType, 2)
+}
+
+// Providers returns every provider block for the named provider.
+func (ws *Workspace) Providers(name string) []*Block {
+	return ws.labelled("provider", name, 1)
+}
+
+func (ws *Workspace) labelled(kind string, label string, labels int) []*Block {
+	var blocks []*Block
+	for _, block := range ws.index.byKind[kind] {
+		if len(block.Labels) == labels && block.Labels[0] == label {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// environmentPath returns the path of a file under environments/<env>.
+func (ws *Workspace) environmentPath(name string) (string, error) {
+	if ws.Environment == "" {
+		return "", fmt.Errorf("no environment selected")
+	}
+	return filepath.Join(ws.Root, "environments", ws.Environment, name), nil
+}
+
+// environmentAttributes parses a flat attribute file such as terraform.tfvars
+// or backend.hcl from the selected environment. Each file is parsed once and
+// shared by every view of the workspace.
+func (ws *Workspace) environmentAttributes(name string) (string, hclsyntax.Attributes, error) {
+	path, err := ws.environmentPath(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ws.index.mu.Lock()
+	defer ws.index.mu.Unlock()
+
+	cached, ok := ws.index.attributes[path]
+	if !ok {
+		cached.attrs, cached.err = ws.parseAttributeFile(path, name)
+		ws.index.attributes[path] = cached
+	}
+
+	return path, cached.attrs, cached.err
+}
+
+func (ws *Workspace) parseAttributeFile(path string, name string) (hclsyntax.Attributes, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &environmentFileError{
+			rng:         hcl.Range{Filename: path},
+			message:     fmt.Sprintf("missing %s %s", ws.Environment, name),
+			remediation: fmt.Sprintf("create environments/%s/%s", ws.Environment, name),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, diags := hclsyntax.ParseConfig(content, path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		parseErr := &environmentFileError{rng: hcl.Range{Filename: path}, remediation: "fix the syntax error so the file can be parsed"}
+		for _, diag := range diags {
+			if diag.Severity == hcl.DiagError {
+				parseErr.message = fmt.Sprintf("unable to parse HCL: %s; %s", diag.Summary, diag.Detail)
+				if diag.Subject != nil {
+					parseErr.rng = *diag.Subject
+				}
+				break
+			}
+		}
+		return nil, parseErr
+	}
+
+	body, ok := config.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("expected %s to contain a body", path)
+	}
+
+	return body.Attributes, nil
+}
+
+// environmentFileError reports an environment file that is missing or fails
+// to parse. It carries the file's range rather than naming the path in its
+// message, so that the message does not depend on the working directory.
+type environmentFileError struct {
+	rng         hcl.Range
+	message     string
+	remediation string
+}
+
+func (e *environmentFileError) Error() string {
+	return e.rng.Filename + ": " + e.message
+}
+
+// checkBlocks runs check over each block in the workspace's focus and
+// collects the violations.
+func checkBlocks(ws *Workspace, blocks []*Block, check func(*Block) []Violation) []Violation {
+	var violations []Violation
+	for _, block := range blocks {
+		if ws.inFocus(block) {
+			violations = append(violations, check(block)...)
+		}
+	}
+	return violations
+}
+
+// requireBlocks is checkBlocks for rules that also expect at least one block
+// to exist, reporting missing when there are none.
+func requireBlocks(ws *Workspace, blocks []*Block, missing Violation, check func(*Block) []Violation) []Violation {
+	if len(blocks) == 0 {
+		return []Violation{missing}
+	}
+	return checkBlocks(ws, blocks, check)
+}
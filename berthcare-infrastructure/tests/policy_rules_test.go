@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	workspaceOnce   sync.Once
+	sharedWorkspace *policy.Workspace
+	workspaceErr    error
+)
+
+// loadWorkspace parses the repository and registers its declarative rules
+// once per test binary; every test gets a view of the same parsed tree.
+func loadWorkspace(t *testing.T, environment string) *policy.Workspace {
+	t.Helper()
+
+	workspaceOnce.Do(func() {
+		environments, err := policy.ListEnvironments(repoRoot)
+		if err != nil {
+			workspaceErr = err
+			return
+		}
+		if _, workspaceErr = policy.RegisterPolicies(filepath.Join(repoRoot, policy.PoliciesDir), environments); workspaceErr != nil {
+			return
+		}
+		sharedWorkspace, workspaceErr = policy.LoadWorkspace(repoRoot)
+	})
+	require.NoError(t, workspaceErr)
+	require.NotEmpty(t, sharedWorkspace.Files, "expected Terraform files to validate")
+
+	return sharedWorkspace.WithEnvironment(environment)
+}
+
+// requirePolicyRules evaluates the given rules against the repository for one
+// environment and fails the test with every violation found.
+func requirePolicyRules(t *testing.T, environment string, kind string, ruleIDs ...string) {
+	t.Helper()
+
+	ws := loadWorkspace(t, environment)
+
+	engine, err := policy.NewEngine(ruleIDs...)
+	require.NoError(t, err)
+
+	violations := engine.Run(ws)
+	if len(violations) > 0 {
+		lines := make([]string, 0, len(violations))
+		for _, v := range violations {
+			lines = append(lines, v.String())
+		}
+		t.Fatalf("found %s violations:\n%s", kind, strings.Join(lines, "\n"))
+	}
+}
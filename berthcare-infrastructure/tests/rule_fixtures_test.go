@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// ruleFixturesDir holds a known-bad and a known-good Terraform root for every
+// rule, as <rule id in lower case>/fail and <rule id in lower case>/pass.
+const ruleFixturesDir = "testdata/rules"
+
+// wantPattern matches the comments a fixture declares its findings with:
+//
+//	# want ID...            a finding on this line, or on the next line
+//	                        that is not blank when the comment stands alone
+//	# want-file ID...       a finding against the file as a whole
+//	# want-workspace ID...  a finding not tied to any file
+//
+// An ID is repeated once for every finding the rule reports there.
+var wantPattern = regexp.MustCompile(`#\s*want(-file|-workspace)?\s+(.+)$`)
+
+// TestRuleFixtures runs every rule against its fixtures and requires exactly
+// the findings their want comments declare: at least one from the fail
+// fixture and none from the pass fixture, so a rule that silently stops
+// reporting, or starts reporting compliant configuration, fails here.
+func TestRuleFixtures(t *testing.T) {
+	// Registers the declarative rules of the repository.
+	loadWorkspace(t, "")
+
+	entries, err := os.ReadDir(ruleFixturesDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		_, ok := policy.Lookup(strings.ToUpper(entry.Name()))
+		require.True(t, ok, "%s/%s names no rule", ruleFixturesDir, entry.Name())
+	}
+
+	for _, rule := range policy.Rules() {
+		// Plan-only rules never see a configuration; their fixtures are the
+		// plans in testdata/plans.
+		if policy.PlanOnly(rule) {
+			continue
+		}
+
+		t.Run(rule.ID(), func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join(ruleFixturesDir, strings.ToLower(rule.ID()))
+			engine, err := policy.NewEngine(rule.ID())
+			require.NoError(t, err)
+
+			fail := filepath.Join(dir, "fail")
+			want := fixtureExpectations(t, fail)
+			require.True(t, slices.ContainsFunc(want, func(finding string) bool {
+				return strings.HasPrefix(finding, rule.ID()+" ") || finding == rule.ID()
+			}), "%s declares no %s finding", fail, rule.ID())
+			require.Equal(t, want, fixtureFindings(t, rule, engine, fail), "findings of %s", fail)
+
+			pass := filepath.Join(dir, "pass")
+			require.Empty(t, fixtureExpectations(t, pass), "%s must not declare findings", pass)
+			require.Empty(t, fixtureFindings(t, rule, engine, pass), "findings of %s", pass)
+		})
+	}
+}
+
+// fixtureExpectations reads the want comments of every file under root and
+// returns the findings they declare as "ID path:line", "ID path" or "ID",
+// sorted, with paths relative to root.
+func fixtureExpectations(t *testing.T, root string) []string {
+	t.Helper()
+
+	var want []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		var pending []string
+		for i, line := range strings.Split(string(content), "\n") {
+			match := wantPattern.FindStringSubmatchIndex(line)
+			if match == nil {
+				if strings.TrimSpace(line) != "" {
+					for _, id := range pending {
+						want = append(want, fmt.Sprintf("%s %s:%d", id, rel, i+1))
+					}
+					pending = nil
+				}
+				continue
+			}
+
+			ids := strings.Fields(line[match[4]:match[5]])
+			kind := ""
+			if match[2] >= 0 {
+				kind = line[match[2]:match[3]]
+			}
+			for _, id := range ids {
+				switch {
+				case kind == "-workspace":
+					want = append(want, id)
+				case kind == "-file":
+					want = append(want, id+" "+rel)
+				case strings.TrimSpace(line[:match[0]]) == "":
+					pending = append(pending, id)
+				default:
+					want = append(want, fmt.Sprintf("%s %s:%d", id, rel, i+1))
+				}
+			}
+		}
+		require.Empty(t, pending, "%s ends with a want comment annotating nothing", path)
+		return nil
+	})
+	require.NoError(t, err)
+
+	slices.Sort(want)
+	return want
+}
+
+// fixtureFindings evaluates engine, which runs rule, against root and every
+// environment under it and returns the findings in the form
+// fixtureExpectations does. An environment-scoped rule is only evaluated for
+// an environment, so its fixtures must have at least one.
+func fixtureFindings(t *testing.T, rule policy.Rule, engine *policy.Engine, root string) []string {
+	t.Helper()
+
+	ws, err := policy.LoadWorkspace(root)
+	require.NoError(t, err)
+	results, err := engine.RunMatrix(ws)
+	require.NoError(t, err)
+
+	if rule.Scope() == policy.ScopeEnvironment {
+		require.True(t, slices.ContainsFunc(results, func(result policy.Result) bool {
+			return result.Rule.ID() == rule.ID() && result.Environment != ""
+		}), "%s has no environments to evaluate %s for", root, rule.ID())
+	}
+
+	var found []string
+	for _, result := range results {
+		for _, v := range result.Violations {
+			if v.Range.Filename == "" {
+				found = append(found, v.RuleID)
+				continue
+			}
+
+			rel, err := filepath.Rel(root, v.Range.Filename)
+			require.NoError(t, err)
+			if v.Range.Start.Line == 0 {
+				found = append(found, v.RuleID+" "+filepath.ToSlash(rel))
+			} else {
+				found = append(found, fmt.Sprintf("%s %s:%d", v.RuleID, filepath.ToSlash(rel), v.Range.Start.Line))
+			}
+		}
+	}
+
+	slices.Sort(found)
+	return found
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSARIFRuleIndex requires every result's ruleIndex to point at its own
+// rule, including rules the report was not given, which code scanning would
+// otherwise attribute to whichever rule came first.
+func TestSARIFRuleIndex(t *testing.T) {
+	rds, ok := policy.Lookup("RDS-001")
+	require.True(t, ok)
+	syntax, ok := policy.Lookup("HCL-001")
+	require.True(t, ok)
+
+	report := &policy.Report{
+		Root:  "infra",
+		Rules: []policy.Rule{rds},
+		Results: []policy.Result{
+			{Rule: rds, Violations: []policy.Violation{{RuleID: "RDS-001", Severity: policy.SeverityError, Message: "not encrypted"}}},
+			{Rule: syntax, Violations: []policy.Violation{{RuleID: "HCL-001", Severity: policy.SeverityError, Message: "unable to parse HCL"}}},
+			{Violations: []policy.Violation{{RuleID: "DECL-001", Severity: policy.SeverityWarning, Message: "declared elsewhere"}}},
+		},
+	}
+	reporter, ok := policy.LookupReporter("sarif")
+	require.True(t, ok)
+	var out bytes.Buffer
+	require.NoError(t, reporter.Write(&out, report))
+
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID                   string `json:"id"`
+						DefaultConfiguration struct {
+							Level string `json:"level"`
+						} `json:"defaultConfiguration"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+
+	rules := log.Runs[0].Tool.Driver.Rules
+	require.Len(t, rules, 3)
+	require.Equal(t, "warning", rules[2].DefaultConfiguration.Level)
+	require.Len(t, log.Runs[0].Results, 3)
+	for _, result := range log.Runs[0].Results {
+		require.Equal(t, result.RuleID, rules[result.RuleIndex].ID)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepository commits files to a new git repository and returns its
+// directory.
+func gitRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	runGit(t, dir, "init", "--quiet")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "--quiet", "-m", "base")
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+}
+
+// TestSince evaluates only what changed since a git revision, and what the
+// changes affect, without losing violations they introduce elsewhere.
+func TestSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := gitRepository(t, map[string]string{
+		"variables.tf": "variable \"encrypt\" {\n  default = false\n}\n",
+		"untouched.tf": "resource \"aws_db_instance\" \"untouched\" {\n  storage_encrypted = false\n}\n",
+		"referrer.tf":  "resource \"aws_db_instance\" \"referrer\" {\n  storage_encrypted = var.encrypt\n}\n",
+		"modules.tf":   "module \"db\" {\n  source  = \"./modules/db\"\n  encrypt = false\n}\n",
+		"modules/db/main.tf": "variable \"encrypt\" {}\n\n" +
+			"resource \"aws_db_instance\" \"this\" {\n  storage_encrypted = var.encrypt\n}\n",
+		"network.tf": "resource \"aws_security_group\" \"db\" {\n  ingress {\n    from_port       = 5432\n    to_port         = 5432\n" +
+			"    protocol        = \"tcp\"\n    security_groups = [\"sg-1\"]\n  }\n}\n",
+	})
+
+	engine, err := policy.NewEngine("RDS-001", "RDS-005")
+	require.NoError(t, err)
+	since := func(t *testing.T) ([]string, *policy.ChangeSet) {
+		t.Helper()
+		ws, err := policy.LoadWorkspace(dir)
+		require.NoError(t, err)
+		results, changes, err := engine.RunSinceContext(context.Background(), ws, nil, "HEAD")
+		require.NoError(t, err)
+
+		var found []string
+		for _, result := range results {
+			for _, v := range result.Violations {
+				found = append(found, v.RuleID+" "+v.Resource)
+			}
+		}
+		return found, changes
+	}
+
+	t.Run("nothing changed", func(t *testing.T) {
+		found, changes := since(t)
+		require.Empty(t, changes.Files)
+		require.Empty(t, found)
+	})
+
+	t.Run("dependents of a changed variable", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{"variables.tf": "variable \"encrypt\" {\n  default = true\n}\n"})
+		defer runGit(t, dir, "checkout", "--", ".")
+
+		found, changes := since(t)
+		require.Equal(t, []string{filepath.Join(dir, "variables.tf")}, changes.Files)
+		require.Equal(t, []string{"aws_db_instance.referrer", "var.encrypt"}, changes.Affected)
+		require.Equal(t, []string{"RDS-001 aws_db_instance.referrer"}, found)
+	})
+
+	t.Run("modules called with changed arguments", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{"modules.tf": "module \"db\" {\n  source  = \"./modules/db\"\n  encrypt = !true\n}\n"})
+		defer runGit(t, dir, "checkout", "--", ".")
+
+		found, changes := since(t)
+		require.Equal(t, []string{"module.db", "module.db.aws_db_instance.this", "module.db.var.encrypt"}, changes.Affected)
+		// RDS-001 names the resource by its address within the module.
+		require.Equal(t, []string{"RDS-001 aws_db_instance.this"}, found)
+	})
+
+	t.Run("violations introduced elsewhere", func(t *testing.T) {
+		// Without the security group the postgres ingress rule has nothing
+		// to check, which it reports against no resource or file.
+		require.NoError(t, os.Remove(filepath.Join(dir, "network.tf")))
+		writeFiles(t, dir, map[string]string{"new.tf": "resource \"aws_db_instance\" \"new\" {\n  storage_encrypted = true\n}\n"})
+		defer func() {
+			require.NoError(t, os.Remove(filepath.Join(dir, "new.tf")))
+			runGit(t, dir, "checkout", "--", ".")
+		}()
+
+		found, changes := since(t)
+		require.Equal(t, []string{filepath.Join(dir, "new.tf")}, changes.Files)
+		require.Equal(t, []string{"RDS-005 "}, found)
+	})
+
+	t.Run("deleted files", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "network.tf")))
+		defer runGit(t, dir, "checkout", "--", ".")
+
+		found, changes := since(t)
+		require.Empty(t, changes.Files)
+		require.Equal(t, []string{filepath.Join(dir, "network.tf")}, changes.Deleted)
+		require.Equal(t, []string{"RDS-005 "}, found)
+	})
+
+	t.Run("dependents of a removed variable", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{"variables.tf": "\n"})
+		defer runGit(t, dir, "checkout", "--", ".")
+
+		found, changes := since(t)
+		// The referrer depended on the variable at HEAD; it no longer
+		// depends on anything the changed file declares.
+		require.Equal(t, []string{"aws_db_instance.referrer", "var.encrypt"}, changes.Affected)
+		require.Equal(t, []string{"RDS-001 aws_db_instance.referrer"}, found)
+	})
+
+	t.Run("renamed files", func(t *testing.T) {
+		runGit(t, dir, "mv", "network.tf", "security.tf")
+		defer runGit(t, dir, "reset", "--quiet", "--hard")
+
+		found, changes := since(t)
+		require.Equal(t, []string{filepath.Join(dir, "security.tf")}, changes.Files)
+		require.Equal(t, []string{filepath.Join(dir, "network.tf")}, changes.Deleted)
+		require.Equal(t, []string{"aws_security_group.db"}, changes.Affected)
+		require.Empty(t, found)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		ws, err := policy.LoadWorkspace(dir)
+		require.NoError(t, err)
+		_, _, err = engine.RunSinceContext(context.Background(), ws, nil, "no-such-branch")
+		require.ErrorContains(t, err, "no-such-branch is not a commit")
+	})
+}
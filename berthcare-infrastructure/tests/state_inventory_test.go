@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+const stateFixture = "testdata/state/dev.tfstate"
+
+// TestStateInventory reads a version 4 state file, checks the security rules
+// against the attribute values it records, and compares its inventory with
+// the resources the configuration declares.
+func TestStateInventory(t *testing.T) {
+	state, err := policy.LoadState(stateFixture)
+	require.NoError(t, err)
+
+	t.Run("inventory", func(t *testing.T) {
+		inventory := map[string][]string{}
+		for _, resource := range state.StateResources() {
+			inventory[resource.Address] = resource.Instances
+		}
+		require.Len(t, inventory, 10)
+		require.Equal(t, []string{"data.aws_caller_identity.current"}, inventory["data.aws_caller_identity.current"])
+		require.Equal(t, []string{`module.s3.aws_s3_bucket.legacy_uploads["2019"]`, `module.s3.aws_s3_bucket.legacy_uploads["2020"]`}, inventory["module.s3.aws_s3_bucket.legacy_uploads"])
+	})
+
+	t.Run("security rules read recorded values", func(t *testing.T) {
+		engine, err := policy.NewEngine()
+		require.NoError(t, err)
+
+		var evaluated []string
+		found := map[string][]string{}
+		for _, result := range engine.RunEnvironments(state, nil) {
+			evaluated = append(evaluated, result.Rule.ID())
+			for _, v := range result.Violations {
+				require.Equal(t, filepath.FromSlash(stateFixture), v.Range.Filename)
+				found[v.RuleID] = append(found[v.RuleID], v.Resource+": "+v.Message)
+			}
+		}
+		require.ElementsMatch(t, []string{"ALB-001", "ALB-002", "RDS-001", "RDS-002", "S3-001", "S3-002", "S3-003"}, evaluated)
+		require.Equal(t, map[string][]string{
+			"ALB-001": {"module.ecs.aws_lb_listener.https: ssl_policy must enforce TLS 1.2+"},
+			"S3-002":  {"module.s3.aws_s3_bucket.exports: versioning.enabled must be true"},
+			"S3-003":  {"module.s3.aws_s3_bucket_public_access_block.exports: block_public_policy must be true"},
+		}, found)
+	})
+
+	t.Run("drift against configuration", func(t *testing.T) {
+		drift := policy.CompareState(loadWorkspace(t, ""), state)
+		require.Equal(t, []string{"module.s3.aws_s3_bucket.legacy_uploads"}, drift.OnlyInState)
+		require.Contains(t, drift.OnlyInCode, "module.vpc.aws_vpc.this")
+		require.Contains(t, drift.OnlyInCode, "aws_acm_certificate_validation.this")
+		for _, recorded := range []string{"aws_acm_certificate.this", "module.s3.aws_s3_bucket.photos", "module.rds.aws_db_instance.this", "module.ecs.aws_lb_listener.https"} {
+			require.NotContains(t, drift.OnlyInCode, recorded)
+		}
+	})
+}
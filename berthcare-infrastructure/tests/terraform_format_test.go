@@ -1,22 +1,21 @@
 package tests
 
 import (
-	"os"
-	"os/exec"
-	"path/filepath"
 	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTerraformFormatCompliance(t *testing.T) {
 	t.Run("Feature: infrastructure-repository-setup, Property 7: Terraform Format Compliance", func(t *testing.T) {
-		workingDir := filepath.Clean(repoRoot)
-
-		cmd := exec.Command("terraform", "fmt", "-check", "-recursive")
-		cmd.Dir = workingDir
-		cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
+		edits, err := policy.FormatFiles(repoRoot)
+		require.NoError(t, err)
 
-		if out, err := cmd.CombinedOutput(); err != nil {
-			t.Fatalf("terraform fmt -check failed: %v\n%s", err, string(out))
+		for _, edit := range edits {
+			diff, err := edit.UnifiedDiff()
+			require.NoError(t, err)
+			t.Errorf("%s is not formatted; run berthcare-policy fmt --write:\n%s", edit.Path, diff)
 		}
 	})
 }
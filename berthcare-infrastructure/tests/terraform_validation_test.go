@@ -1,32 +1,142 @@
 package tests
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
 )
 
+// TestTerraformValidation validates the repository offline. It needs
+// terraform and a provider mirror written by
+//
+//	terraform providers mirror "$TERRAFORM_PLUGIN_DIR"
+//
+// and is skipped without them.
 func TestTerraformValidation(t *testing.T) {
 	t.Run("Feature: infrastructure-repository-setup, Property 6: Terraform Validation Success", func(t *testing.T) {
-		workingDir := filepath.Clean(repoRoot)
+		if _, err := exec.LookPath("terraform"); err != nil {
+			t.Skip("terraform not installed")
+		}
+		pluginDir := os.Getenv("TERRAFORM_PLUGIN_DIR")
+		if pluginDir == "" {
+			t.Skip("TERRAFORM_PLUGIN_DIR does not name a provider mirror")
+		}
 
-		_ = os.RemoveAll(filepath.Join(workingDir, ".terraform"))
-		_ = os.Remove(filepath.Join(workingDir, ".terraform.lock.hcl"))
+		validator := policy.Validator{PluginDir: pluginDir, Timeout: 5 * time.Minute}
+		results, err := validator.Validate(context.Background(), repoRoot)
+		require.NoError(t, err)
 
-		initCmd := exec.Command("terraform", "init", "-backend=false", "-input=false")
-		initCmd.Dir = workingDir
-		initCmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
-		if out, err := initCmd.CombinedOutput(); err != nil {
-			t.Fatalf("terraform init failed: %v\n%s", err, string(out))
+		for _, result := range results {
+			for _, v := range result.Violations {
+				if v.Severity == policy.SeverityError {
+					t.Errorf("terraform validate of %s: %s", result.Dir, v)
+				}
+			}
 		}
-		defer os.RemoveAll(filepath.Join(workingDir, ".terraform"))
+	})
+}
 
-		validateCmd := exec.Command("terraform", "validate")
-		validateCmd.Dir = workingDir
-		validateCmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
-		if out, err := validateCmd.CombinedOutput(); err != nil {
-			t.Fatalf("terraform validate failed: %v\n%s", err, string(out))
-		}
+// fakeTerraform stands in for terraform. It logs each command with the
+// directory it ran in, reports an error for any module with a broken.tf and
+// a warning for any other, and hangs in any module with a slow.tf.
+const fakeTerraform = `#!/bin/sh
+lock=unlocked
+[ -e .terraform.lock.hcl ] && lock=locked
+echo "$PWD $lock $*" >> "$FAKE_TERRAFORM_LOG"
+[ -e slow.tf ] && exec sleep 10
+case "$1" in
+init)
+  mkdir -p .terraform
+  ;;
+validate)
+  if [ -e broken.tf ]; then
+    echo '{"valid":false,"diagnostics":[{"severity":"error","summary":"Unsupported argument","detail":"An argument named \"colour\" is not expected here.","address":"aws_s3_bucket.this","range":{"filename":"broken.tf","start":{"line":2,"column":3,"byte":34},"end":{"line":2,"column":9,"byte":40}}}]}'
+    exit 1
+  fi
+  echo '{"valid":true,"diagnostics":[{"severity":"warning","summary":"Deprecated attribute","detail":""}]}'
+  ;;
+esac
+`
+
+// TestValidator runs the validator against a fake terraform to check that
+// it leaves the configuration alone, validates every module and reads the
+// diagnostics.
+func TestValidator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake terraform is a shell script")
+	}
+
+	bin := t.TempDir()
+	terraform := filepath.Join(bin, "terraform")
+	require.NoError(t, os.WriteFile(terraform, []byte(fakeTerraform), 0o755))
+	log := filepath.Join(bin, "log")
+	t.Setenv("FAKE_TERRAFORM_LOG", log)
+	mirror := t.TempDir()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf":                  "module \"a\" {\n  source = \"./modules/a\"\n}\n",
+		".terraform.lock.hcl":      "# pinned elsewhere\n",
+		"modules/a/main.tf":        "",
+		"modules/b/broken.tf":      "resource \"aws_s3_bucket\" \"this\" {\n  colour = \"red\"\n}\n",
+		"modules/b/README.md":      "not a module of its own\n",
+		"tests/testdata/ignore.tf": "",
+	})
+
+	validator := policy.Validator{Terraform: terraform, PluginDir: mirror, Timeout: 10 * time.Second}
+	results, err := validator.Validate(context.Background(), dir)
+	require.NoError(t, err)
+
+	var modules []string
+	for _, result := range results {
+		modules = append(modules, result.Dir)
+	}
+	require.Equal(t, []string{".", filepath.Join("modules", "a"), filepath.Join("modules", "b")}, modules)
+
+	for _, result := range results[:2] {
+		require.Len(t, result.Violations, 1)
+		require.Equal(t, policy.SeverityWarning, result.Violations[0].Severity)
+		require.Empty(t, result.Violations[0].Range.Filename)
+	}
+	require.Len(t, results[2].Violations, 1)
+	v := results[2].Violations[0]
+	require.Equal(t, "TF-001", v.RuleID)
+	require.Equal(t, policy.SeverityError, v.Severity)
+	require.Equal(t, "aws_s3_bucket.this", v.Resource)
+	require.Equal(t, filepath.Join(dir, "modules", "b", "broken.tf"), v.Range.Filename)
+	require.Equal(t, 2, v.Range.Start.Line)
+	require.Equal(t, `Unsupported argument; An argument named "colour" is not expected here.`, v.Message)
+
+	logged, err := os.ReadFile(log)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(logged)), "\n")
+	require.Len(t, lines, 6)
+	for _, line := range lines {
+		require.NotContains(t, line, dir, "terraform must run in a copy of the configuration")
+		require.Contains(t, line, " unlocked ", "the lock file must not be copied")
+	}
+	require.Contains(t, lines[0], "init -backend=false -input=false -no-color -plugin-dir="+mirror)
+	require.Contains(t, lines[1], "validate -json")
+	require.NoDirExists(t, filepath.Join(dir, ".terraform"))
+	require.FileExists(t, filepath.Join(dir, ".terraform.lock.hcl"))
+
+	t.Run("timeout", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{"modules/a/slow.tf": ""})
+		validator.Timeout = 100 * time.Millisecond
+		_, err := validator.Validate(context.Background(), dir)
+		require.ErrorContains(t, err, "module "+filepath.Join("modules", "a")+": terraform init did not finish within 100ms")
+	})
+
+	t.Run("no provider mirror", func(t *testing.T) {
+		_, err := policy.Validator{Terraform: terraform}.Validate(context.Background(), dir)
+		require.ErrorContains(t, err, "provider mirror")
 	})
 }
@@ -0,0 +1,63 @@
+# One rule per operator, written against the repository's own configuration.
+
+rule "FIXTURE-001" {
+  description   = "Dev keeps backups for two weeks"
+  severity      = "warning"
+  resource_type = "aws_db_instance"
+  attribute     = "backup_retention_period"
+  operator      = "at_least"
+  value         = 14
+  environments  = ["dev"]
+}
+
+rule "FIXTURE-002" {
+  description   = "Dev runs on burstable database instances"
+  severity      = "info"
+  resource_type = "aws_db_instance"
+  attribute     = "instance_class"
+  operator      = "matches"
+  value         = "^db\\.t3\\."
+  environments  = ["dev"]
+}
+
+rule "FIXTURE-003" {
+  description   = "Databases run MySQL or MariaDB"
+  severity      = "error"
+  resource_type = "aws_db_instance"
+  attribute     = "engine"
+  operator      = "in"
+  value         = ["mysql", "mariadb"]
+}
+
+rule "FIXTURE-004" {
+  description   = "Security groups do not allow egress on every protocol"
+  severity      = "warning"
+  resource_type = "aws_security_group"
+  attribute     = "egress.protocol"
+  operator      = "not_equals"
+  value         = "-1"
+  remediation   = "name the protocols the resource needs"
+}
+
+rule "FIXTURE-005" {
+  description   = "Databases set provisioned IOPS"
+  severity      = "info"
+  resource_type = "aws_db_instance"
+  attribute     = "iops"
+  operator      = "present"
+}
+
+rule "FIXTURE-006" {
+  description   = "Databases leave skip_final_snapshot at its default"
+  severity      = "error"
+  resource_type = "aws_db_instance"
+  attribute     = "skip_final_snapshot"
+  operator      = "absent"
+
+  trace {
+    feature      = "aws-dev-environment"
+    property     = 2
+    name         = "RDS Security Compliance"
+    requirements = ["2.5"]
+  }
+}
@@ -0,0 +1,13 @@
+resource "aws_acm_certificate" "this" {      # want ACM-001
+  domain_name       = "staging.berthcare.ca" # want ACM-001
+  validation_method = "EMAIL"                # want ACM-001
+}
+
+resource "aws_acm_certificate" "api" {
+  domain_name       = var.domain_name
+  validation_method = "DNS"
+
+  lifecycle {
+    create_before_destroy = false # want ACM-001
+  }
+}
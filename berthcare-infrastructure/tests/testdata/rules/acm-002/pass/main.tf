@@ -0,0 +1,7 @@
+resource "aws_route53_record" "certificate_validation" {
+  zone_id = var.route53_zone_id
+  name    = "_validation.dev.berthcare.ca"
+  type    = "CNAME"
+  records = ["_validation.acm-validations.aws"]
+  ttl     = 60
+}
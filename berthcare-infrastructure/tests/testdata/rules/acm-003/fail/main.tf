@@ -0,0 +1,3 @@
+resource "aws_acm_certificate_validation" "this" {                                # want ACM-003
+  certificate_arn = "arn:aws:acm:ca-central-1:123456789012:certificate/berthcare" # want ACM-003
+}
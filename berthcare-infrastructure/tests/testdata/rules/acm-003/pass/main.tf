@@ -0,0 +1,17 @@
+resource "aws_acm_certificate" "this" {
+  domain_name       = "dev.berthcare.ca"
+  validation_method = "DNS"
+}
+
+resource "aws_route53_record" "certificate_validation" {
+  zone_id = "Z0123456789ABCDEFGHIJ"
+  name    = "_validation.dev.berthcare.ca"
+  type    = "CNAME"
+  records = ["_validation.acm-validations.aws"]
+  ttl     = 60
+}
+
+resource "aws_acm_certificate_validation" "this" {
+  certificate_arn         = aws_acm_certificate.this.arn
+  validation_record_fqdns = [aws_route53_record.certificate_validation.fqdn]
+}
@@ -0,0 +1,13 @@
+resource "aws_lb_listener" "https" {
+  load_balancer_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:loadbalancer/app/berthcare/1"
+  port              = 443
+  protocol          = "HTTP"                      # want ALB-001
+  ssl_policy        = "ELBSecurityPolicy-2016-08" # want ALB-001
+  certificate_arn   = ""                          # want ALB-001
+}
+
+resource "aws_lb_listener" "admin" { # want ALB-001 ALB-001
+  load_balancer_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:loadbalancer/app/berthcare/1"
+  port              = 443
+  protocol          = "HTTPS"
+}
@@ -0,0 +1,12 @@
+resource "aws_acm_certificate" "this" {
+  domain_name       = "dev.berthcare.ca"
+  validation_method = "DNS"
+}
+
+resource "aws_lb_listener" "https" {
+  load_balancer_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:loadbalancer/app/berthcare/1"
+  port              = 443
+  protocol          = "HTTPS"
+  ssl_policy        = "ELBSecurityPolicy-TLS13-1-2-2021-06"
+  certificate_arn   = aws_acm_certificate.this.arn
+}
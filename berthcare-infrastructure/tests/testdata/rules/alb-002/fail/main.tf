@@ -0,0 +1,26 @@
+resource "aws_lb_listener" "http" { # want ALB-002
+  load_balancer_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:loadbalancer/app/berthcare/1"
+  port              = 80
+  protocol          = "HTTPS" # want ALB-002
+
+  default_action { # want ALB-002
+    type             = "forward"
+    target_group_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:targetgroup/app/1"
+  }
+}
+
+resource "aws_lb_listener" "legacy" {
+  load_balancer_arn = "arn:aws:elasticloadbalancing:ca-central-1:123456789012:loadbalancer/app/berthcare/1"
+  port              = 80
+  protocol          = "HTTP"
+
+  default_action {
+    type = "redirect"
+
+    redirect { # want ALB-002 ALB-002
+      port        = "8443"
+      protocol    = "HTTPS"
+      status_code = "HTTP_302"
+    }
+  }
+}
@@ -0,0 +1,5 @@
+# want-file BACKEND-001
+bucket  = "berthcare-state"                # want BACKEND-001
+key     = "envs/staging/terraform.tfstate" # want BACKEND-001
+region  = "ca-central-1"
+encrypt = false # want BACKEND-001
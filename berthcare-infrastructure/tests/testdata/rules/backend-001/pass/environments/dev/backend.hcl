@@ -0,0 +1,5 @@
+bucket         = "berthcare-terraform-state"
+key            = "envs/dev/terraform.tfstate"
+region         = "ca-central-1"
+dynamodb_table = "berthcare-terraform-locks"
+encrypt        = true
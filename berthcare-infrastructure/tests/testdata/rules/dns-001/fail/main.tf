@@ -0,0 +1,26 @@
+module "ecs" {
+  source = "./modules/ecs"
+}
+
+resource "aws_route53_record" "app" {
+  zone_id = var.route53_zone_id
+  name    = var.domain_name
+  type    = "CNAME" # want DNS-001
+
+  alias {
+    name                   = "berthcare-123456789.ca-central-1.elb.amazonaws.com" # want DNS-001
+    zone_id                = module.ecs.alb_zone_id
+    evaluate_target_health = false # want DNS-001
+  }
+}
+
+resource "aws_route53_record" "api" {
+  zone_id = var.route53_zone_id
+  name    = "api.berthcare.ca" # want DNS-001
+  type    = "A"
+
+  alias { # want DNS-001
+    name    = module.ecs.alb_dns_name
+    zone_id = module.ecs.alb_zone_id
+  }
+}
@@ -0,0 +1,11 @@
+resource "aws_lb" "main" {
+  name = "berthcare"
+}
+
+output "alb_dns_name" {
+  value = aws_lb.main.dns_name
+}
+
+output "alb_zone_id" {
+  value = aws_lb.main.zone_id
+}
@@ -0,0 +1,16 @@
+resource "aws_subnet" "public" {
+  vpc_id                  = "vpc-0123456789abcdef0"
+  cidr_block              = "10.0.1.0/24"
+  map_public_ip_on_launch = true
+}
+
+resource "aws_autoscaling_group" "ecs" {
+  min_size            = 1
+  max_size            = 2
+  vpc_zone_identifier = [aws_subnet.public.id] # want ECS-001
+}
+
+resource "aws_autoscaling_group" "workers" { # want ECS-001
+  min_size = 1
+  max_size = 2
+}
@@ -0,0 +1,10 @@
+resource "aws_subnet" "private" {
+  vpc_id     = "vpc-0123456789abcdef0"
+  cidr_block = "10.0.11.0/24"
+}
+
+resource "aws_autoscaling_group" "ecs" {
+  min_size            = 1
+  max_size            = 2
+  vpc_zone_identifier = [aws_subnet.private.id]
+}
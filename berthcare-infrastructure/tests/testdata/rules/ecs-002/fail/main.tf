@@ -0,0 +1,14 @@
+resource "aws_subnet" "private" {
+  vpc_id     = "vpc-0123456789abcdef0"
+  cidr_block = "10.0.11.0/24"
+}
+
+resource "aws_lb" "main" {
+  name    = "berthcare"
+  subnets = [aws_subnet.private.id] # want ECS-002
+}
+
+resource "aws_lb" "internal" {
+  name    = "berthcare-internal"
+  subnets = "subnet-0123456789abcdef0" # want ECS-002
+}
@@ -0,0 +1,21 @@
+variable "nat_gateway_id" {
+  type = string
+}
+
+resource "aws_nat_gateway" "main" {
+  subnet_id = "subnet-0123456789abcdef0"
+}
+
+resource "aws_route_table" "private" {
+  vpc_id = "vpc-0123456789abcdef0"
+
+  route {
+    cidr_block     = "10.0.0.0/8" # want ECS-003
+    nat_gateway_id = aws_nat_gateway.main.id
+  }
+
+  route {
+    cidr_block     = "0.0.0.0/0"
+    nat_gateway_id = var.nat_gateway_id # want ECS-003
+  }
+}
@@ -0,0 +1,12 @@
+resource "aws_nat_gateway" "main" {
+  subnet_id = "subnet-0123456789abcdef0"
+}
+
+resource "aws_route_table" "private" {
+  vpc_id = "vpc-0123456789abcdef0"
+
+  route {
+    cidr_block     = "0.0.0.0/0"
+    nat_gateway_id = aws_nat_gateway.main.id
+  }
+}
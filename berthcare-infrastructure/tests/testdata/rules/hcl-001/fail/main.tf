@@ -0,0 +1,4 @@
+resource "aws_s3_bucket" "photos" {
+  bucket = "berthcare-photos"
+  acl    = = "private" # want HCL-001
+}
@@ -0,0 +1,42 @@
+variable "secret_arns" {
+  type = list(string)
+}
+
+data "aws_iam_policy_document" "deploy" {
+  statement {
+    sid       = "Everything"
+    actions   = ["*"] # want IAM-001
+    resources = ["arn:aws:s3:::berthcare-photos"]
+  }
+
+  statement {
+    sid       = "AllOfS3"
+    actions   = ["s3:GetObject", "s3:*"] # want IAM-001
+    resources = ["arn:aws:s3:::berthcare-photos/*"]
+  }
+
+  # The sid is on the allowlist, but not for this action.
+  statement {
+    sid       = "ECRGetAuth"
+    actions   = ["ecr:GetAuthorizationToken", "ecr:PutImage"]
+    resources = ["*"] # want IAM-001
+  }
+
+  # Only dev gives every secret; the finding is reported once.
+  statement {
+    actions   = ["secretsmanager:GetSecretValue"]
+    resources = var.secret_arns # want IAM-001
+  }
+
+  statement {
+    sid         = "AllButIAM"
+    not_actions = ["iam:*"] # want IAM-001
+    resources   = ["arn:aws:s3:::berthcare-photos"]
+  }
+
+  statement {
+    sid           = "AllButPhotos"
+    actions       = ["s3:GetObject"]
+    not_resources = ["arn:aws:s3:::berthcare-photos/*"] # want IAM-001
+  }
+}
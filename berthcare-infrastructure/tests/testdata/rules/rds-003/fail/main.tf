@@ -0,0 +1,15 @@
+variable "backup_retention_days" {
+  type    = number
+  default = 7
+}
+
+resource "aws_db_instance" "main" {
+  engine                  = "postgres"
+  instance_class          = "db.t3.micro"
+  backup_retention_period = var.backup_retention_days # want RDS-003
+}
+
+resource "aws_db_instance" "replica" { # want RDS-003
+  engine         = "postgres"
+  instance_class = "db.t3.micro"
+}
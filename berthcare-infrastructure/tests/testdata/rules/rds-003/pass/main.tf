@@ -0,0 +1,10 @@
+variable "backup_retention_days" {
+  type    = number
+  default = 1
+}
+
+resource "aws_db_instance" "main" {
+  engine                  = "postgres"
+  instance_class          = "db.t3.micro"
+  backup_retention_period = var.backup_retention_days
+}
@@ -0,0 +1,14 @@
+resource "aws_subnet" "public" {
+  vpc_id                  = "vpc-0123456789abcdef0"
+  cidr_block              = "10.0.1.0/24"
+  map_public_ip_on_launch = true
+}
+
+resource "aws_db_subnet_group" "main" {
+  name       = "berthcare-db"
+  subnet_ids = [aws_subnet.public.id] # want RDS-004
+}
+
+resource "aws_db_subnet_group" "reporting" { # want RDS-004
+  name = "berthcare-reporting"
+}
@@ -0,0 +1,10 @@
+resource "aws_subnet" "private" {
+  vpc_id                  = "vpc-0123456789abcdef0"
+  cidr_block              = "10.0.11.0/24"
+  map_public_ip_on_launch = false
+}
+
+resource "aws_db_subnet_group" "main" {
+  name       = "berthcare-db"
+  subnet_ids = [aws_subnet.private.id]
+}
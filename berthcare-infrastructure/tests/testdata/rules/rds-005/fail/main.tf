@@ -0,0 +1,24 @@
+resource "aws_security_group" "db" {
+  name   = "berthcare-db"
+  vpc_id = "vpc-0123456789abcdef0"
+
+  ingress { # want RDS-005
+    from_port   = 5432
+    to_port     = 5432
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16"]
+  }
+}
+
+resource "aws_security_group" "reporting" {
+  name   = "berthcare-reporting"
+  vpc_id = "vpc-0123456789abcdef0"
+
+  ingress {
+    from_port       = 5432
+    to_port         = 5432
+    protocol        = "tcp"
+    security_groups = ["sg-0123456789abcdef0"]
+    cidr_blocks     = ["0.0.0.0/0"] # want RDS-005
+  }
+}
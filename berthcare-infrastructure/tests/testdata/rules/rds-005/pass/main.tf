@@ -0,0 +1,20 @@
+resource "aws_ecs_cluster" "main" {
+  name = "berthcare"
+}
+
+resource "aws_security_group" "tasks" {
+  name   = "berthcare-tasks"
+  vpc_id = "vpc-0123456789abcdef0"
+}
+
+resource "aws_security_group" "db" {
+  name   = "berthcare-db"
+  vpc_id = "vpc-0123456789abcdef0"
+
+  ingress {
+    from_port       = 5432
+    to_port         = 5432
+    protocol        = "tcp"
+    security_groups = [aws_security_group.tasks.id]
+  }
+}
@@ -0,0 +1,9 @@
+variable "environment" {
+  type = string
+}
+
+resource "aws_db_instance" "main" {
+  engine              = "postgres"
+  instance_class      = "db.t3.micro"
+  deletion_protection = false # want RDS-006
+}
@@ -0,0 +1,26 @@
+variable "region" {
+  type = string
+}
+
+provider "aws" {
+  region = "us-east-1" # want REGION-001
+}
+
+provider "aws" {
+  alias  = "replica"
+  region = var.region # want REGION-001
+}
+
+resource "aws_s3_bucket_replication_configuration" "photos" {
+  bucket = "berthcare-photos"
+  role   = "arn:aws:iam::123456789012:role/replication"
+
+  rule {
+    status = "Enabled"
+
+    destination {
+      bucket = "arn:aws:s3:::berthcare-photos-replica"
+      region = "ca-west-1" # want REGION-001
+    }
+  }
+}
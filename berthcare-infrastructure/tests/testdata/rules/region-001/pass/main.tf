@@ -0,0 +1,9 @@
+terraform {
+  backend "s3" {
+    region = "ca-central-1"
+  }
+}
+
+provider "aws" {
+  region = "ca-central-1"
+}
@@ -0,0 +1,14 @@
+terraform {
+  backend "s3" {
+    bucket = "berthcare-terraform-state"
+    region = "us-west-2" # want REGION-002
+  }
+}
+
+provider "aws" { # want REGION-002
+}
+
+provider "aws" {
+  alias  = "edge"
+  region = "us-east-1" # want REGION-002
+}
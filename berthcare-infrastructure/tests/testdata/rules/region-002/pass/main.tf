@@ -0,0 +1,10 @@
+terraform {
+  backend "s3" {
+    bucket = "berthcare-terraform-state"
+    region = "ca-central-1"
+  }
+}
+
+provider "aws" {
+  region = "ca-central-1"
+}
@@ -0,0 +1,3 @@
+variable "availability_zones" {
+  type = list(string)
+}
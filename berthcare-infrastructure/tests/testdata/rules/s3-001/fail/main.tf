@@ -0,0 +1,27 @@
+resource "aws_s3_bucket" "photos" { # want S3-001
+  bucket = "berthcare-photos"
+}
+
+resource "aws_s3_bucket" "exports" {
+  bucket = "berthcare-exports"
+
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default {
+        sse_algorithm = "aws:kms" # want S3-001
+      }
+    }
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "berthcare-logs"
+
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default { # want S3-001
+        kms_master_key_id = "alias/logs"
+      }
+    }
+  }
+}
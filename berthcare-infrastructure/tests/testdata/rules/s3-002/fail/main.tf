@@ -0,0 +1,19 @@
+resource "aws_s3_bucket" "photos" { # want S3-002
+  bucket = "berthcare-photos"
+}
+
+resource "aws_s3_bucket" "exports" {
+  bucket = "berthcare-exports"
+
+  versioning {
+    enabled = false # want S3-002
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "berthcare-logs"
+
+  versioning { # want S3-002
+    mfa_delete = false
+  }
+}
@@ -0,0 +1,7 @@
+resource "aws_s3_bucket" "photos" {
+  bucket = "berthcare-photos"
+
+  versioning {
+    enabled = true
+  }
+}
@@ -0,0 +1,12 @@
+resource "aws_s3_bucket_public_access_block" "photos" { # want S3-003 S3-003 S3-003
+  bucket            = "berthcare-photos"
+  block_public_acls = false # want S3-003
+}
+
+resource "aws_s3_bucket_public_access_block" "exports" {
+  bucket                  = "berthcare-exports"
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = "yes" # want S3-003
+}
@@ -0,0 +1,37 @@
+variable "task_role_arn" {
+  type = string
+}
+
+data "aws_iam_policy_document" "photos" {
+  statement {
+    actions   = ["s3:GetObject"]
+    resources = ["arn:aws:s3:::berthcare-photos/*"]
+
+    principals { # want S3-004
+      type        = "Service"
+      identifiers = ["ecs-tasks.amazonaws.com"]
+    }
+  }
+}
+
+data "aws_iam_policy_document" "exports" {
+  statement {
+    actions   = ["s3:GetObject"]
+    resources = ["arn:aws:s3:::berthcare-exports/*"]
+
+    principals {
+      type        = "AWS"
+      identifiers = ["*"] # want S3-004
+    }
+  }
+
+  statement {
+    actions   = ["s3:PutObject"]
+    resources = ["arn:aws:s3:::berthcare-exports/*"]
+
+    principals {
+      type        = "AWS"
+      identifiers = [var.task_role_arn, "arn:aws:iam::123456789012:root"] # want S3-004
+    }
+  }
+}
@@ -0,0 +1,27 @@
+variable "task_role_arn" {
+  type = string
+}
+
+data "aws_iam_policy_document" "photos" {
+  statement {
+    actions   = ["s3:GetObject", "s3:PutObject"]
+    resources = ["arn:aws:s3:::berthcare-photos/*"]
+
+    principals {
+      type        = "AWS"
+      identifiers = [var.task_role_arn]
+    }
+  }
+}
+
+data "aws_iam_policy_document" "exports" {
+  statement {
+    actions   = ["s3:GetObject", "s3:PutObject"]
+    resources = ["arn:aws:s3:::berthcare-exports/*"]
+
+    principals {
+      type        = "AWS"
+      identifiers = [var.task_role_arn]
+    }
+  }
+}
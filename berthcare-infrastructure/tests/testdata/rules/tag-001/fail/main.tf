@@ -0,0 +1,26 @@
+provider "aws" { # want TAG-001
+  region = "ca-central-1"
+}
+
+provider "aws" {
+  alias  = "tagged"
+  region = "ca-central-1"
+
+  default_tags {
+    tags = { # want TAG-001
+      Project = "berthcare"
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "elsewhere"
+  region = "ca-central-1"
+
+  default_tags {
+    tags = {
+      Project = "berthcare"
+      Region  = "us-east-1" # want TAG-001
+    }
+  }
+}
@@ -0,0 +1,10 @@
+provider "aws" {
+  region = "ca-central-1"
+
+  default_tags {
+    tags = {
+      Project = "berthcare"
+      Region  = "ca-central-1"
+    }
+  }
+}
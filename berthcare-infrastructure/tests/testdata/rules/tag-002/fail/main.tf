@@ -0,0 +1,19 @@
+variable "project_name" {
+  type    = string
+  default = "berthcare"
+}
+
+variable "environment" {
+  type = string
+}
+
+provider "aws" {
+  region = "ca-central-1"
+
+  default_tags {
+    tags = { # want TAG-002
+      Project     = var.project_name
+      Environment = "staging" # want TAG-002
+    }
+  }
+}
@@ -0,0 +1,4 @@
+# want-workspace VPC-001 VPC-001
+module "vpc" {
+  source = "./modules/vpc"
+}
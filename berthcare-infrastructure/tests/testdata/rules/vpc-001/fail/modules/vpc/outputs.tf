@@ -0,0 +1,7 @@
+output "vpc_id" {
+  value = "vpc-0123456789abcdef0"
+}
+
+output "public_subnet_ids" {
+  value = ["subnet-0123456789abcdef0"]
+}
@@ -0,0 +1,15 @@
+output "vpc_id" {
+  value = "vpc-0123456789abcdef0"
+}
+
+output "public_subnet_ids" {
+  value = ["subnet-0123456789abcdef0"]
+}
+
+output "private_subnet_ids" {
+  value = ["subnet-0fedcba9876543210"]
+}
+
+output "nat_gateway_ids" {
+  value = ["nat-0123456789abcdef0"]
+}
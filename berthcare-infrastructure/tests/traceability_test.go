@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"berthcare-infrastructure/tests/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceability checks the **Feature** and **Validates** annotations on
+// the tests against the properties and requirements the rules declare: every
+// property a rule enforces must have a test, and every requirement a rule
+// satisfies must be validated by one.
+func TestTraceability(t *testing.T) {
+	// Register the declarative rules so that their traces are checked too.
+	loadWorkspace(t, "")
+
+	annotations, err := policy.ScanAnnotations(".")
+	require.NoError(t, err)
+
+	t.Run("annotations", func(t *testing.T) {
+		found, err := policy.ScanAnnotations(filepath.Join("testdata", "traceability"))
+		require.NoError(t, err)
+
+		var got []string
+		for _, a := range found {
+			require.Equal(t, "TestAnnotated", a.Test)
+			require.Equal(t, "annotated_test.go", filepath.Base(a.File))
+			got = append(got, fmt.Sprintf("%s %v", a.Property, a.Requirements))
+		}
+		// A Validates line applies to every Feature line since the previous
+		// one, and to no later one.
+		require.Equal(t, []string{
+			"aws-dev-environment, Property 4: ECS Private Subnet Placement [2.4 4.5]",
+			"aws-dev-environment, Property 2: RDS Security Compliance [2.4 4.5]",
+			"aws-staging-environment, Property 1: Regional Compliance []",
+			"infrastructure-repository-setup, Property 7: Terraform Format Compliance []",
+			"aws-dev-environment, VPC outputs defined [3.6]",
+		}, got)
+	})
+
+	t.Run("repository tests", func(t *testing.T) {
+		require.NotEmpty(t, annotations)
+		for _, a := range annotations {
+			require.NotEmpty(t, a.Test)
+			require.Positive(t, a.Line, "%s", a.Test)
+			require.NotEmpty(t, a.Property.Feature, "%s:%d", a.File, a.Line)
+			require.NotEmpty(t, a.Property.Name, "%s:%d", a.File, a.Line)
+		}
+	})
+
+	matrix := policy.BuildMatrix(policy.Rules(), annotations)
+	for _, p := range matrix.Untested {
+		t.Errorf("%s is enforced by a rule but no test is annotated with it", p)
+	}
+	for _, r := range matrix.Unvalidated {
+		t.Errorf("requirement %s is satisfied by a rule but no test validates it", r)
+	}
+	// The suppression and destructive change rules guard the tooling and the
+	// apply process rather than a feature's design, and no design has a
+	// property for the least privilege the IAM rules enforce yet.
+	require.Equal(t, []string{"IAM-001", "IAM-002", "PLAN-001", "POLICY-001"}, matrix.Untraced)
+}
@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// **Feature: aws-dev-environment, Property 3: S3 Security Compliance**
+// **Validates: Requirements 3.5**
+func TestVariableResolution(t *testing.T) {
+	t.Run("Feature: aws-dev-environment, Property 3: S3 Security Compliance", func(t *testing.T) {
+		ws := loadWorkspace(t, "dev")
+		root := ws.Evaluator(ws.RootModule())
+
+		// Set in environments/dev/terraform.tfvars.
+		require.Equal(t, cty.StringVal("arn:aws:iam::123456789012:role/berthcare-dev-task"), root.Variable("task_role_arn"))
+		// Left to its default in variables.tf.
+		require.Equal(t, cty.StringVal("berthcare.internal"), root.Variable("internal_zone_name"))
+
+		block := findBlock(t, ws, ".", "local")
+		attr, ok := block.Body.Attributes["github_oidc_subjects"]
+		require.True(t, ok, "expected local.github_oidc_subjects")
+		subjects, diags := root.Value(attr.Expr)
+		require.False(t, diags.HasErrors(), diags.Error())
+		require.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("repo:BerthCare/BerthCare:environment:dev")}), subjects)
+
+		instances := ws.Instances("modules/s3")
+		require.Len(t, instances, 1, "expected modules/s3 to be called once from the root module")
+		require.Equal(t, root.Variable("task_role_arn"), ws.Evaluator(instances[0]).Variable("task_role_arn"))
+	})
+}
@@ -139,6 +139,12 @@ variable "backup_retention_period" {
   default     = 7
 }
 
+variable "deletion_protection" {
+  type        = bool
+  description = "Protect the RDS instance from deletion. Only dev, which is torn down with terraform destroy, turns it off."
+  default     = true
+}
+
 variable "task_role_arn" {
   type        = string
   description = "IAM role ARN for ECS tasks (used for S3 bucket policies)."